@@ -165,6 +165,7 @@ type Config struct {
 	KubernetesEastWestRangePredicates       []*eskip.Predicate  `yaml:"-"`
 	KubernetesOnlyAllowedExternalNames      bool                `yaml:"kubernetes-only-allowed-external-names"`
 	KubernetesAllowedExternalNames          regexpListFlag      `yaml:"kubernetes-allowed-external-names"`
+	KubernetesAllowLocalExternalNames       bool                `yaml:"kubernetes-allow-local-external-names"`
 
 	// Default filters
 	DefaultFiltersDir string `yaml:"default-filters-dir"`
@@ -423,6 +424,7 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.KubernetesEastWestRangePredicatesString, "kubernetes-east-west-range-predicates", "", "set the predicates that will be appended to routes identified as to -kubernetes-east-west-range-domains")
 	flag.BoolVar(&cfg.KubernetesOnlyAllowedExternalNames, "kubernetes-only-allowed-external-names", false, "only accept external name services, route group network backends and route group explicit LB endpoints from an allow list defined by zero or more -kubernetes-allowed-external-name flags")
 	flag.Var(&cfg.KubernetesAllowedExternalNames, "kubernetes-allowed-external-name", "set zero or more regular expressions from which at least one should be matched by the external name services, route group network addresses and explicit endpoints domain names")
+	flag.BoolVar(&cfg.KubernetesAllowLocalExternalNames, "kubernetes-allow-local-external-names", false, "accept external name services pointing to loopback, link-local or cloud metadata addresses, e.g. localhost or 169.254.169.254")
 
 	// Auth:
 	flag.BoolVar(&cfg.EnableOAuth2GrantFlow, "enable-oauth2-grant-flow", false, "enables OAuth2 Grant Flow filter")
@@ -615,6 +617,7 @@ func (c *Config) ToRouteSrvOptions() routesrv.Options {
 		Address:                            c.Address,
 		DefaultFiltersDir:                  c.DefaultFiltersDir,
 		KubernetesAllowedExternalNames:     c.KubernetesAllowedExternalNames,
+		KubernetesAllowLocalExternalNames:  c.KubernetesAllowLocalExternalNames,
 		KubernetesInCluster:                c.KubernetesInCluster,
 		KubernetesURL:                      c.KubernetesURL,
 		KubernetesHealthcheck:              c.KubernetesHealthcheck,
@@ -767,6 +770,7 @@ func (c *Config) ToOptions() skipper.Options {
 		KubernetesEastWestRangePredicates:  c.KubernetesEastWestRangePredicates,
 		KubernetesOnlyAllowedExternalNames: c.KubernetesOnlyAllowedExternalNames,
 		KubernetesAllowedExternalNames:     c.KubernetesAllowedExternalNames,
+		KubernetesAllowLocalExternalNames:  c.KubernetesAllowLocalExternalNames,
 
 		// API Monitoring:
 		ApiUsageMonitoringEnable:                c.ApiUsageMonitoringEnable,
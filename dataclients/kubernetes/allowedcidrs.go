@@ -0,0 +1,83 @@
+package kubernetes
+
+import (
+	"net"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperAllowedCIDRsAnnotationKey = "zalando.org/skipper-allowed-cidrs"
+
+// allowedSource parses the allowed CIDRs annotation, a comma separated list of
+// CIDRs, and returns the source predicate matching only the requests from
+// these networks.
+func (ing *ingress) allowedSource(m *definitions.Metadata, logger *log.Entry) *eskip.Predicate {
+	val, ok := m.Annotations[skipperAllowedCIDRsAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	return ing.sourceCIDRsPredicate(skipperAllowedCIDRsAnnotationKey, val, logger)
+}
+
+// sourceCIDRsPredicate parses the value of an annotation, a comma separated
+// list of CIDRs, and returns the source predicate matching only the requests
+// from these networks, respecting the ReverseSourcePredicate option.
+func (ing *ingress) sourceCIDRsPredicate(key, val string, logger *log.Entry) *eskip.Predicate {
+	var args []interface{}
+	for _, c := range strings.Split(val, ",") {
+		_, n, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			logger.Errorf("Invalid %s annotation, invalid CIDR %q: %s", key, strings.TrimSpace(c), val)
+			return nil
+		}
+
+		args = append(args, n.String())
+	}
+
+	return &eskip.Predicate{
+		Name: sourcePredicateName(ing.reverseSourcePredicate),
+		Args: args,
+	}
+}
+
+// deniedSourceRoute creates the route responding with 403 to the requests
+// from outside the allowed networks. It is the copy of a route of the ingress
+// without the source predicate, so the original route takes precedence for
+// the requests from the allowed networks.
+func deniedSourceRoute(r *eskip.Route, source *eskip.Predicate) *eskip.Route {
+	dr := companionRoute(r, "denied", 0)
+	p := dr.Predicates[:0]
+	for _, pi := range dr.Predicates {
+		if !samePredicate(pi, source) {
+			p = append(p, pi)
+		}
+	}
+
+	dr.Predicates = p
+
+	shuntCompanionRoute(dr, &eskip.Filter{
+		Name: filters.StatusName,
+		Args: []interface{}{403.0},
+	})
+
+	return dr
+}
+
+func samePredicate(a, b *eskip.Predicate) bool {
+	if a.Name != b.Name || len(a.Args) != len(b.Args) {
+		return false
+	}
+
+	for i := range a.Args {
+		if a.Args[i] != b.Args[i] {
+			return false
+		}
+	}
+
+	return true
+}
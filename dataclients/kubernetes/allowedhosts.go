@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+const skipperAllowedHostsAnnotationKey = "zalando.org/skipper-allowed-hosts"
+
+// allowedHosts is the list of hosts that an ingress is allowed to serve,
+// defined by the zalando.org/skipper-allowed-hosts annotation. The rules of
+// the ingress with other hosts are ignored, and the routes without a host get
+// a host predicate matching only the allowed hosts, so requests to any other
+// host are not matched by the routes of the ingress.
+type allowedHosts struct {
+	hosts map[string]bool
+	rx    string
+}
+
+// parse allowed hosts annotation, a comma separated list of host names
+func allowedHostsAnnotation(m *definitions.Metadata, hostPortRx string, logger *log.Entry) *allowedHosts {
+	val, ok := m.Annotations[skipperAllowedHostsAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var list []string
+	hosts := make(map[string]bool)
+	for _, h := range strings.Split(val, ",") {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" || strings.ContainsAny(h, ":/ ") {
+			logger.Errorf("Invalid %s annotation, invalid host %q: %s", skipperAllowedHostsAnnotationKey, h, val)
+			return nil
+		}
+
+		if !hosts[h] {
+			hosts[h] = true
+			list = append(list, h)
+		}
+	}
+
+	return &allowedHosts{
+		hosts: hosts,
+		rx:    createHostRxPort(hostPortRx, list...),
+	}
+}
+
+// allows tells whether a rule with the given host can be served. Rules
+// without a host are allowed, and restricted to the allowed hosts when
+// applying the annotations.
+func (a *allowedHosts) allows(host string) bool {
+	return a == nil || host == "" || a.hosts[strings.ToLower(host)]
+}
+
+// restrict sets the host predicate of the allowed hosts on a route that
+// doesn't have one.
+func (a *allowedHosts) restrict(r *eskip.Route) {
+	if a == nil || len(r.HostRegexps) > 0 {
+		return
+	}
+
+	r.HostRegexps = []string{a.rx}
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperAuthAnnotationKey = "zalando.org/skipper-auth"
+
+const (
+	authTypeOAuth2             = "oauth2"
+	authTypeTokenintrospection = "tokenintrospection"
+	authTypeJwt                = "jwt"
+
+	authMatchAll = "all"
+	authMatchAny = "any"
+)
+
+// authConfig is the configuration of the zalando.org/skipper-auth annotation,
+// a shorthand for the authentication filters:
+//
+//	{"type": "oauth2", "scopes": ["uid"]}
+//	{"type": "tokenintrospection", "issuer": "https://issuer.example.org", "claims": ["sub"]}
+//	{"type": "jwt", "issuer": "https://issuer.example.org"}
+//
+// By default, all the listed scopes or claims are required. With "match": "any",
+// any of them is sufficient.
+type authConfig struct {
+	Type   string   `json:"type"`
+	Scopes []string `json:"scopes"`
+	Issuer string   `json:"issuer"`
+	Claims []string `json:"claims"`
+	Match  string   `json:"match"`
+}
+
+// parse auth annotation, and create the authentication filters
+func authFilters(m *definitions.Metadata) ([]*eskip.Filter, error) {
+	val, ok := m.Annotations[skipperAuthAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var ac authConfig
+	if err := json.Unmarshal([]byte(val), &ac); err != nil {
+		return nil, fmt.Errorf("error while parsing %s annotation: %w", skipperAuthAnnotationKey, err)
+	}
+
+	if ac.Match != "" && ac.Match != authMatchAll && ac.Match != authMatchAny {
+		return nil, fmt.Errorf("invalid %s annotation, unsupported match: %s", skipperAuthAnnotationKey, ac.Match)
+	}
+
+	matchAny := ac.Match == authMatchAny
+	switch ac.Type {
+	case authTypeOAuth2:
+		if len(ac.Scopes) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation, scopes are required for type %s", skipperAuthAnnotationKey, ac.Type)
+		}
+
+		name := filters.OAuthTokeninfoAllScopeName
+		if matchAny {
+			name = filters.OAuthTokeninfoAnyScopeName
+		}
+
+		return []*eskip.Filter{{Name: name, Args: stringArgs(ac.Scopes...)}}, nil
+	case authTypeTokenintrospection:
+		if ac.Issuer == "" || len(ac.Claims) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation, issuer and claims are required for type %s", skipperAuthAnnotationKey, ac.Type)
+		}
+
+		name := filters.OAuthTokenintrospectionAllClaimsName
+		if matchAny {
+			name = filters.OAuthTokenintrospectionAnyClaimsName
+		}
+
+		return []*eskip.Filter{{Name: name, Args: stringArgs(append([]string{ac.Issuer}, ac.Claims...)...)}}, nil
+	case authTypeJwt:
+		if ac.Issuer == "" {
+			return nil, fmt.Errorf("invalid %s annotation, issuer is required for type %s", skipperAuthAnnotationKey, ac.Type)
+		}
+
+		return []*eskip.Filter{{Name: filters.JwtValidationName, Args: stringArgs(ac.Issuer)}}, nil
+	default:
+		return nil, fmt.Errorf("invalid %s annotation, unsupported type: %s", skipperAuthAnnotationKey, ac.Type)
+	}
+}
+
+func stringArgs(s ...string) []interface{} {
+	args := make([]interface{}, len(s))
+	for i := range s {
+		args[i] = s[i]
+	}
+
+	return args
+}
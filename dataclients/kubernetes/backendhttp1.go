@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperBackendHTTP1AnnotationKey = "zalando.org/skipper-backend-http1"
+
+// application protocols of the service ports, that declare HTTP/2 backends
+var http2AppProtocols = map[string]bool{
+	"kubernetes.io/h2c": true,
+	"h2c":               true,
+	"http2":             true,
+	"grpc":              true,
+}
+
+// parse backend http1 annotation
+func backendHTTP1Annotation(m *definitions.Metadata, logger *log.Entry) bool {
+	val, ok := m.Annotations[skipperBackendHTTP1AnnotationKey]
+	if !ok {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, boolean expected: %s", skipperBackendHTTP1AnnotationKey, val)
+		return false
+	}
+
+	return enabled
+}
+
+// http2ServicePort tells whether the service port declares HTTP/2 as its
+// application protocol. Unknown services and ports are not reported here,
+// they are handled by the route conversion.
+func http2ServicePort(state *clusterState, namespace, name string, port func(*service) (*servicePort, error)) (*servicePort, bool) {
+	svc, err := state.getService(namespace, name)
+	if err != nil {
+		return nil, false
+	}
+
+	sp, err := port(svc)
+	if err != nil {
+		return nil, false
+	}
+
+	return sp, http2AppProtocols[strings.ToLower(sp.AppProtocol)]
+}
+
+// backendHTTP1Filter returns the backendHTTP1 filter for the routes of an
+// ingress with the backend http1 annotation. Forcing HTTP/1.1 conflicts
+// with the backends declared to speak HTTP/2, in which case the annotation
+// is rejected.
+func backendHTTP1Filter(i *definitions.IngressItem, state *clusterState, logger *log.Entry) *eskip.Filter {
+	if !backendHTTP1Annotation(i.Metadata, logger) {
+		return nil
+	}
+
+	backends := []*definitions.Backend{i.Spec.DefaultBackend}
+	for _, rule := range i.Spec.Rules {
+		if rule.Http == nil {
+			continue
+		}
+
+		for _, p := range rule.Http.Paths {
+			backends = append(backends, p.Backend)
+		}
+	}
+
+	for _, b := range backends {
+		if b == nil {
+			continue
+		}
+
+		if sp, ok := http2ServicePort(state, i.Metadata.Namespace, b.ServiceName, func(s *service) (*servicePort, error) {
+			return s.getServicePort(b.ServicePort)
+		}); ok {
+			logger.Errorf("Invalid %s annotation, conflicting with the HTTP/2 port %s of service %s", skipperBackendHTTP1AnnotationKey, sp.Name, b.ServiceName)
+			return nil
+		}
+	}
+
+	return &eskip.Filter{Name: filters.BackendHTTP1Name}
+}
+
+// backendHTTP1FilterV1 is the same as backendHTTP1Filter, for the
+// networking.k8s.io/v1 ingresses.
+func backendHTTP1FilterV1(i *definitions.IngressV1Item, state *clusterState, logger *log.Entry) *eskip.Filter {
+	if !backendHTTP1Annotation(i.Metadata, logger) {
+		return nil
+	}
+
+	backends := []*definitions.BackendV1{i.Spec.DefaultBackend}
+	for _, rule := range i.Spec.Rules {
+		if rule.Http == nil {
+			continue
+		}
+
+		for _, p := range rule.Http.Paths {
+			backends = append(backends, p.Backend)
+		}
+	}
+
+	for _, b := range backends {
+		if b == nil {
+			continue
+		}
+
+		if sp, ok := http2ServicePort(state, i.Metadata.Namespace, b.Service.Name, func(s *service) (*servicePort, error) {
+			return s.getServicePortV1(b.Service.Port)
+		}); ok {
+			logger.Errorf("Invalid %s annotation, conflicting with the HTTP/2 port %s of service %s", skipperBackendHTTP1AnnotationKey, sp.Name, b.Service.Name)
+			return nil
+		}
+	}
+
+	return &eskip.Filter{Name: filters.BackendHTTP1Name}
+}
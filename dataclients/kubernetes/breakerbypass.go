@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperBreakerBypassAnnotationKey = "zalando.org/skipper-breaker-bypass"
+
+// sourcePredicateName returns the name of the source predicate, the same one
+// used by the healthcheck routes, respecting the ReverseSourcePredicate option.
+func sourcePredicateName(reverseSourcePredicate bool) string {
+	if reverseSourcePredicate {
+		return predicates.SourceFromLastName
+	}
+
+	return predicates.SourceName
+}
+
+// breakerBypassRoute creates the route for the operators to bypass the circuit
+// breakers of a route. It matches only the requests from the networks of the
+// annotation, and proxies them to the same backend as the route, without the
+// breaker filters.
+func breakerBypassRoute(r *eskip.Route, source *eskip.Predicate) *eskip.Route {
+	br := companionRoute(r, "breaker_bypass", 0, eskip.CopyPredicate(source))
+
+	f := []*eskip.Filter{{Name: filters.DisableBreakerName}}
+	for _, fi := range br.Filters {
+		switch fi.Name {
+		case filters.ConsecutiveBreakerName, filters.RateBreakerName, filters.DisableBreakerName:
+		default:
+			f = append(f, fi)
+		}
+	}
+
+	br.Filters = f
+	return br
+}
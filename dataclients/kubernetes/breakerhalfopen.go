@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperBreakerHalfOpenAnnotationKey = "zalando.org/skipper-breaker-halfopen"
+
+// parse breaker half-open annotation, and set the number of the half-open
+// requests, used to probe the backend, in the breaker filters of the ingress.
+// The breaker filters are defined by the zalando.org/skipper-filter
+// annotation. The missing optional arguments before the half-open requests
+// are set to 0, which means to use the global breaker settings.
+func setBreakerHalfOpen(f []*eskip.Filter, m *definitions.Metadata, logger *log.Entry) {
+	val, ok := m.Annotations[skipperBreakerHalfOpenAnnotationKey]
+	if !ok {
+		return
+	}
+
+	halfOpen, err := strconv.Atoi(val)
+	if err != nil || halfOpen <= 0 {
+		logger.Errorf("Invalid %s annotation, positive integer expected: %s", skipperBreakerHalfOpenAnnotationKey, val)
+		return
+	}
+
+	var found bool
+	for _, fi := range f {
+		var index int
+		switch fi.Name {
+		case filters.ConsecutiveBreakerName:
+			index = 2
+		case filters.RateBreakerName:
+			index = 3
+		default:
+			continue
+		}
+
+		for len(fi.Args) <= index {
+			fi.Args = append(fi.Args, float64(0))
+		}
+
+		fi.Args[index] = float64(halfOpen)
+		found = true
+	}
+
+	if !found {
+		logger.Errorf("Invalid %s annotation, no consecutiveBreaker or rateBreaker filter found", skipperBreakerHalfOpenAnnotationKey)
+	}
+}
@@ -0,0 +1,57 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/predicates/clientcert"
+)
+
+const skipperClientCertMatchAnnotationKey = "zalando.org/skipper-client-cert-match"
+
+// parse client cert match annotation, and create a ClientCert predicate
+// matching the TLS client certificate attributes, e.g. {"subject": "CN=admin"}
+func clientCertPredicate(m *definitions.Metadata, logger *log.Entry) *eskip.Predicate {
+	val, ok := m.Annotations[skipperClientCertMatchAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var attributes map[string]string
+	if err := json.Unmarshal([]byte(val), &attributes); err != nil {
+		logger.Errorf("Invalid %s annotation, can not parse: %v", skipperClientCertMatchAnnotationKey, err)
+		return nil
+	}
+
+	if len(attributes) == 0 {
+		logger.Errorf("Invalid %s annotation, no attributes", skipperClientCertMatchAnnotationKey)
+		return nil
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		switch k {
+		case clientcert.AttributeSubject, clientcert.AttributeIssuer, clientcert.AttributeSAN:
+		default:
+			logger.Errorf("Invalid %s annotation, unknown attribute: %s", skipperClientCertMatchAnnotationKey, k)
+			return nil
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	args := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, k, attributes[k])
+	}
+
+	return &eskip.Predicate{
+		Name: predicates.ClientCertName,
+		Args: args,
+	}
+}
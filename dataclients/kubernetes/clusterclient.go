@@ -13,6 +13,7 @@ import (
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -33,6 +34,8 @@ const (
 	ServicesClusterURI         = "/api/v1/services"
 	EndpointsClusterURI        = "/api/v1/endpoints"
 	SecretsClusterURI          = "/api/v1/secrets"
+	NodesClusterURI            = "/api/v1/nodes"
+	PodsNamespaceFmt           = "/api/v1/namespaces/%s/pods"
 	defaultKubernetesURL       = "http://localhost:8001"
 	IngressesNamespaceFmt      = "/apis/extensions/v1beta1/namespaces/%s/ingresses"
 	IngressesV1NamespaceFmt    = "/apis/networking.k8s.io/v1/namespaces/%s/ingresses"
@@ -43,6 +46,7 @@ const (
 	serviceAccountDir          = "/var/run/secrets/kubernetes.io/serviceaccount/"
 	serviceAccountTokenKey     = "token"
 	serviceAccountRootCAKey    = "ca.crt"
+	maxNodeCapacityWeight      = 64
 )
 
 const RouteGroupsNotInstalledMessage = `RouteGroups CRD is not installed in the cluster.
@@ -54,6 +58,7 @@ type clusterClient struct {
 	servicesURI         string
 	endpointsURI        string
 	secretsURI          string
+	nodesURI            string
 	tokenProvider       secrets.SecretsProvider
 	apiURL              string
 	certificateRegistry *certregistry.CertRegistry
@@ -63,12 +68,19 @@ type clusterClient struct {
 	httpClient      *http.Client
 	ingressV1       bool
 
-	loggedMissingRouteGroups bool
+	ingressClassAnnotationPrecedence bool
+	nodeCapacityLabel                string
+	notReadyBehavior                 NotReadyBehavior
+	lenientListParsing               bool
+	latencyProvider                  EndpointLatencyProvider
+	loggedMissingRouteGroups         bool
 }
 
 var (
 	errResourceNotFound     = errors.New("resource not found")
 	errServiceNotFound      = errors.New("service not found")
+	errAllEndpointsNotReady = errors.New("all endpoints not ready")
+	errServiceWithoutPorts  = errors.New("service has no ports")
 	errAPIServerURLNotFound = errors.New("kubernetes API server URL could not be constructed from env vars")
 	errInvalidCertificate   = errors.New("invalid CA")
 )
@@ -148,11 +160,18 @@ func newClusterClient(o Options, apiURL, ingCls, rgCls string, quit <-chan struc
 		servicesURI:         ServicesClusterURI,
 		endpointsURI:        EndpointsClusterURI,
 		secretsURI:          SecretsClusterURI,
+		nodesURI:            NodesClusterURI,
 		ingressClass:        ingClsRx,
 		routeGroupClass:     rgClsRx,
 		httpClient:          httpClient,
 		apiURL:              apiURL,
 		certificateRegistry: o.CertificateRegistry,
+
+		ingressClassAnnotationPrecedence: o.IngressClassAnnotationPrecedence,
+		nodeCapacityLabel:                o.NodeCapacityLabel,
+		notReadyBehavior:                 o.StartupNotReadyBehavior,
+		lenientListParsing:               o.LenientListParsing,
+		latencyProvider:                  o.EndpointLatencyProvider,
 	}
 
 	if o.KubernetesInCluster {
@@ -286,21 +305,64 @@ func (c *clusterClient) filterIngressesByClass(items []*definitions.IngressItem)
 	return validIngs
 }
 
+// ingressV1Class returns the effective class of an ingress, and whether it is taken from
+// the class annotation. When both the class annotation (v1beta1 style) and
+// spec.ingressClassName (v1 style) are set and they differ, the spec wins, unless the
+// annotation precedence is configured.
+func (c *clusterClient) ingressV1Class(ing *definitions.IngressV1Item) (string, bool) {
+	var annotationClass, specClass string
+	if ing.Metadata != nil {
+		annotationClass = ing.Metadata.Annotations[ingressClassKey]
+	}
+
+	if ing.Spec != nil {
+		specClass = ing.Spec.IngressClassName
+	}
+
+	switch {
+	case annotationClass == "":
+		return specClass, false
+	case specClass == "":
+		return annotationClass, true
+	case annotationClass != specClass:
+		winner := specClass
+		if c.ingressClassAnnotationPrecedence {
+			winner = annotationClass
+		}
+
+		var ns, name string
+		if ing.Metadata != nil {
+			ns, name = ing.Metadata.Namespace, ing.Metadata.Name
+		}
+
+		log.Debugf(
+			"Conflicting ingress class for ingress %s/%s, annotation: %s, spec: %s, using: %s",
+			ns, name, annotationClass, specClass, winner,
+		)
+
+		return winner, c.ingressClassAnnotationPrecedence
+	default:
+		return specClass, false
+	}
+}
+
 // filterIngressesV1ByClass will filter only the ingresses that have the valid class, these are
 // the defined one, empty string class or not class at all
 func (c *clusterClient) filterIngressesV1ByClass(items []*definitions.IngressV1Item) []*definitions.IngressV1Item {
 	validIngs := []*definitions.IngressV1Item{}
 
 	for _, ing := range items {
-		// v1beta1 style
-		if c.ingressClassMissmatch(ing.Metadata) {
+		// TODO(sszuecs) we need also to fetch ingressclass object and check what should be done
+		cls, annotated := c.ingressV1Class(ing)
+		if annotated {
+			if c.ingressClassMissmatch(ing.Metadata) {
+				continue
+			}
+		} else if cls != "" && !c.ingressClass.MatchString(cls) {
 			continue
 		}
 
-		// v1 style, TODO(sszuecs) we need also to fetch ingressclass object and check what should be done
-		if ing.Spec == nil || ing.Spec.IngressClassName == "" || c.ingressClass.MatchString(ing.Spec.IngressClassName) {
-			validIngs = append(validIngs, ing)
-		}
+		validIngs = append(validIngs, ing)
 	}
 
 	return validIngs
@@ -324,9 +386,32 @@ func sortByMetadata(slice interface{}, getMetadata func(int) *definitions.Metada
 	})
 }
 
+// getIngressListJSON requests an ingress list, and parses it with the parse
+// function, when lenient list parsing is enabled, logging the skipped items.
+func (c *clusterClient) getIngressListJSON(list interface{}, parse func([]byte) ([]error, error)) error {
+	if !c.lenientListParsing {
+		return c.getJSON(c.ingressesURI, list)
+	}
+
+	var raw json.RawMessage
+	if err := c.getJSON(c.ingressesURI, &raw); err != nil {
+		return err
+	}
+
+	diagnostics, err := parse(raw)
+	for _, d := range diagnostics {
+		log.Errorf("Skipping malformed ingress: %v", d)
+	}
+
+	return err
+}
+
 func (c *clusterClient) loadIngresses() ([]*definitions.IngressItem, error) {
 	var il definitions.IngressList
-	if err := c.getJSON(c.ingressesURI, &il); err != nil {
+	if err := c.getIngressListJSON(&il, func(d []byte) (diagnostics []error, err error) {
+		il, diagnostics, err = definitions.ParseIngressJSONLenient(d)
+		return
+	}); err != nil {
 		log.Debugf("requesting all ingresses failed: %v", err)
 		return nil, err
 	}
@@ -340,7 +425,10 @@ func (c *clusterClient) loadIngresses() ([]*definitions.IngressItem, error) {
 
 func (c *clusterClient) loadIngressesV1() ([]*definitions.IngressV1Item, error) {
 	var il definitions.IngressV1List
-	if err := c.getJSON(c.ingressesURI, &il); err != nil {
+	if err := c.getIngressListJSON(&il, func(d []byte) (diagnostics []error, err error) {
+		il, diagnostics, err = definitions.ParseIngressV1JSONLenient(d)
+		return
+	}); err != nil {
 		log.Debugf("requesting all ingresses failed: %v", err)
 		return nil, err
 	}
@@ -444,6 +532,64 @@ func (c *clusterClient) loadEndpoints() (map[definitions.ResourceID]*endpoint, e
 	return result, nil
 }
 
+// loadNodeWeights returns the weight of the endpoints by node name, based on
+// the capacity label of the nodes. Only the nodes with a valid label are
+// included.
+func (c *clusterClient) loadNodeWeights() (map[string]int, error) {
+	var nodes nodeList
+	if err := c.getJSON(c.nodesURI, &nodes); err != nil {
+		log.Debugf("requesting all nodes failed: %v", err)
+		return nil, err
+	}
+
+	log.Debugf("all nodes received: %d", len(nodes.Items))
+	result := make(map[string]int)
+	for _, node := range nodes.Items {
+		if node == nil || node.Meta == nil {
+			continue
+		}
+
+		val, ok := node.Meta.Labels[c.nodeCapacityLabel]
+		if !ok {
+			continue
+		}
+
+		weight, err := strconv.Atoi(val)
+		if err != nil || weight <= 0 || weight > maxNodeCapacityWeight {
+			log.Errorf("Invalid capacity label of node %s: %s", node.Meta.Name, val)
+			continue
+		}
+
+		result[node.Meta.Name] = weight
+	}
+
+	return result, nil
+}
+
+// loadPods returns the pods in the given namespaces, used by the endpoint
+// selector and endpoint condition annotations.
+func (c *clusterClient) loadPods(namespaces []string) (map[definitions.ResourceID]*pod, error) {
+	result := make(map[definitions.ResourceID]*pod)
+	for _, ns := range namespaces {
+		var pods podList
+		if err := c.getJSON(fmt.Sprintf(PodsNamespaceFmt, ns), &pods); err != nil {
+			log.Debugf("requesting pods of namespace %s failed: %v", ns, err)
+			return nil, err
+		}
+
+		log.Debugf("pods of namespace %s received: %d", ns, len(pods.Items))
+		for _, pod := range pods.Items {
+			if pod == nil || pod.Meta == nil {
+				continue
+			}
+
+			result[newResourceID(ns, pod.Meta.Name)] = pod
+		}
+	}
+
+	return result, nil
+}
+
 func (c *clusterClient) logMissingRouteGroupsOnce() {
 	if c.loggedMissingRouteGroups {
 		return
@@ -459,6 +605,8 @@ func (c *clusterClient) fetchClusterState() (*clusterState, error) {
 		ingressesV1 []*definitions.IngressV1Item
 		ingresses   []*definitions.IngressItem
 		secrets     map[definitions.ResourceID]*secret
+		nodeWeights map[string]int
+		pods        map[definitions.ResourceID]*pod
 	)
 	if c.ingressV1 {
 		ingressesV1, err = c.loadIngressesV1()
@@ -496,15 +644,37 @@ func (c *clusterClient) fetchClusterState() (*clusterState, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else if hasIngressTLSV1(ingressesV1) {
+		// without a certificate registry, the secrets are only used to validate the TLS
+		// entries of the ingresses, so a failed request must not fail the update
+		secrets, _ = c.loadSecrets()
+	}
+
+	if c.nodeCapacityLabel != "" {
+		nodeWeights, err = c.loadNodeWeights()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if namespaces := podNamespaces(ingresses, ingressesV1, services); len(namespaces) > 0 {
+		pods, err = c.loadPods(namespaces)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &clusterState{
-		ingresses:       ingresses,
-		ingressesV1:     ingressesV1,
-		routeGroups:     routeGroups,
-		services:        services,
-		endpoints:       endpoints,
-		secrets:         secrets,
-		cachedEndpoints: make(map[endpointID][]string),
+		ingresses:        ingresses,
+		ingressesV1:      ingressesV1,
+		routeGroups:      routeGroups,
+		services:         services,
+		endpoints:        endpoints,
+		secrets:          secrets,
+		nodeWeights:      nodeWeights,
+		pods:             pods,
+		notReadyBehavior: c.notReadyBehavior,
+		latencyProvider:  c.latencyProvider,
+		cachedEndpoints:  make(map[endpointID][]string),
 	}, nil
 }
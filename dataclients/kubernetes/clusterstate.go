@@ -10,13 +10,17 @@ import (
 )
 
 type clusterState struct {
-	ingresses       []*definitions.IngressItem
-	ingressesV1     []*definitions.IngressV1Item
-	routeGroups     []*definitions.RouteGroupItem
-	services        map[definitions.ResourceID]*service
-	endpoints       map[definitions.ResourceID]*endpoint
-	secrets         map[definitions.ResourceID]*secret
-	cachedEndpoints map[endpointID][]string
+	ingresses        []*definitions.IngressItem
+	ingressesV1      []*definitions.IngressV1Item
+	routeGroups      []*definitions.RouteGroupItem
+	services         map[definitions.ResourceID]*service
+	endpoints        map[definitions.ResourceID]*endpoint
+	secrets          map[definitions.ResourceID]*secret
+	nodeWeights      map[string]int
+	pods             map[definitions.ResourceID]*pod
+	notReadyBehavior NotReadyBehavior
+	latencyProvider  EndpointLatencyProvider
+	cachedEndpoints  map[endpointID][]string
 }
 
 func (state *clusterState) getService(namespace, name string) (*service, error) {
@@ -42,11 +46,15 @@ func (state *clusterState) getServiceRG(namespace, name string) (*service, error
 	return s, nil
 }
 
-func (state *clusterState) getEndpointsByService(namespace, name, protocol string, servicePort *servicePort) []string {
+func (state *clusterState) getEndpointsByService(namespace, name, protocol string, servicePort *servicePort, selector endpointSelector) []string {
+	// the endpoint ports are matched by the name of the service port, so
+	// it is part of the cache key, too
 	epID := endpointID{
-		ResourceID: newResourceID(namespace, name),
-		protocol:   protocol,
-		targetPort: servicePort.TargetPort.String(),
+		ResourceID:  newResourceID(namespace, name),
+		protocol:    protocol,
+		servicePort: servicePort.Name,
+		targetPort:  servicePort.TargetPort.String(),
+		selector:    selector.String(),
 	}
 
 	if cached, ok := state.cachedEndpoints[epID]; ok {
@@ -58,8 +66,17 @@ func (state *clusterState) getEndpointsByService(namespace, name, protocol strin
 		return nil
 	}
 
-	targets := ep.targetsByServicePort(protocol, servicePort)
+	ep = state.selectEndpoints(epID.ResourceID, ep, selector)
+	targets := ep.targetsByServicePort(protocol, servicePort, state.nodeWeights)
+	if len(targets) == 0 && state.notReadyBehavior == NotReadyRouteAnyway {
+		targets = ep.notReady().targetsByServicePort(protocol, servicePort, state.nodeWeights)
+	}
+
 	sort.Strings(targets)
+	if state.latencyProvider != nil {
+		targets = latencyWeighted(targets, state.latencyProvider)
+	}
+
 	state.cachedEndpoints[epID] = targets
 	return targets
 }
@@ -80,8 +97,45 @@ func (state *clusterState) getEndpointsByTarget(namespace, name, protocol string
 		return nil
 	}
 
-	targets := ep.targetsByServiceTarget(protocol, target)
+	ep = state.selectEndpoints(epID.ResourceID, ep, nil)
+	targets := ep.targetsByServiceTarget(protocol, target, state.nodeWeights)
+	if len(targets) == 0 && state.notReadyBehavior == NotReadyRouteAnyway {
+		targets = ep.notReady().targetsByServiceTarget(protocol, target, state.nodeWeights)
+	}
+
 	sort.Strings(targets)
+	if state.latencyProvider != nil {
+		targets = latencyWeighted(targets, state.latencyProvider)
+	}
+
 	state.cachedEndpoints[epID] = targets
 	return targets
 }
+
+// selectEndpoints returns the endpoint with only those addresses, whose pods
+// match the endpoint selector of the ingress, and have the condition set by
+// the endpoint condition annotation of the service.
+func (state *clusterState) selectEndpoints(id definitions.ResourceID, ep *endpoint, selector endpointSelector) *endpoint {
+	if len(selector) > 0 {
+		selected := ep.selectPods(state.pods, selector.matchesPod)
+		ep = &selected
+	}
+
+	if condition := endpointCondition(state.services[id]); condition != "" {
+		selected := ep.selectConditionPods(condition, state.pods)
+		ep = &selected
+	}
+
+	return ep
+}
+
+// dropNotReady tells whether the routes to a service need to be dropped,
+// because all its endpoints are not ready.
+func (state *clusterState) dropNotReady(namespace, name string) bool {
+	if state.notReadyBehavior != NotReadyDrop {
+		return false
+	}
+
+	ep, ok := state.endpoints[newResourceID(namespace, name)]
+	return ok && ep.allNotReady()
+}
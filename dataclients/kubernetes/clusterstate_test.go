@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+)
+
+func TestEndpointsOrderIsDeterministic(t *testing.T) {
+	addresses := []*address{
+		{IP: "10.2.9.103"},
+		{IP: "10.2.9.9"},
+		{IP: "10.2.9.104"},
+		{IP: "10.2.9.21"},
+		{IP: "10.2.9.1"},
+	}
+
+	sp := &servicePort{
+		Name:       "http",
+		Port:       80,
+		TargetPort: &definitions.BackendPort{Value: 8080},
+	}
+
+	var first []string
+	for i := 0; i < 16; i++ {
+		shuffled := make([]*address, len(addresses))
+		copy(shuffled, addresses)
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		state := &clusterState{
+			endpoints: map[definitions.ResourceID]*endpoint{
+				newResourceID("foo", "bar"): {
+					Meta: &definitions.Metadata{Namespace: "foo", Name: "bar"},
+					Subsets: []*subset{{
+						Addresses: shuffled,
+						Ports:     []*port{{Name: "http", Port: 8080}},
+					}},
+				},
+			},
+			cachedEndpoints: make(map[endpointID][]string),
+		}
+
+		eps := state.getEndpointsByService("foo", "bar", "http", sp, nil)
+		if len(eps) != len(addresses) {
+			t.Fatalf("unexpected number of endpoints: %d", len(eps))
+		}
+
+		if first == nil {
+			first = eps
+			continue
+		}
+
+		if !reflect.DeepEqual(first, eps) {
+			t.Fatalf("endpoints order differs, got: %v, expected: %v", eps, first)
+		}
+	}
+}
+
+func TestEndpointsByServicePort(t *testing.T) {
+	state := &clusterState{
+		endpoints: map[definitions.ResourceID]*endpoint{
+			newResourceID("foo", "bar"): {
+				Meta: &definitions.Metadata{Namespace: "foo", Name: "bar"},
+				Subsets: []*subset{{
+					Addresses: []*address{{IP: "10.2.9.103"}, {IP: "10.2.9.104"}},
+					Ports:     []*port{{Name: "web", Port: 8080}, {Name: "admin", Port: 9090}},
+				}},
+			},
+		},
+		cachedEndpoints: make(map[endpointID][]string),
+	}
+
+	for _, ti := range []struct {
+		servicePort *servicePort
+		expected    []string
+	}{{
+		servicePort: &servicePort{Name: "web", Port: 80, TargetPort: &definitions.BackendPort{Value: "http"}},
+		expected:    []string{"http://10.2.9.103:8080", "http://10.2.9.104:8080"},
+	}, {
+		servicePort: &servicePort{Name: "admin", Port: 81, TargetPort: &definitions.BackendPort{Value: "http"}},
+		expected:    []string{"http://10.2.9.103:9090", "http://10.2.9.104:9090"},
+	}, {
+		servicePort: &servicePort{Name: "web", Port: 80, TargetPort: &definitions.BackendPort{Value: "http"}},
+		expected:    []string{"http://10.2.9.103:8080", "http://10.2.9.104:8080"},
+	}} {
+		eps := state.getEndpointsByService("foo", "bar", "http", ti.servicePort, nil)
+		if !reflect.DeepEqual(eps, ti.expected) {
+			t.Errorf("unexpected endpoints for port %s, got: %v, expected: %v", ti.servicePort.Name, eps, ti.expected)
+		}
+	}
+}
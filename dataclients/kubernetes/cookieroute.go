@@ -0,0 +1,70 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperCookieRouteAnnotationKey = "zalando.org/skipper-cookie-route"
+
+// cookieRoute is the configuration of the cookie based canary routing,
+// defined by the zalando.org/skipper-cookie-route annotation. Requests
+// having the cookie with the configured value are routed to the canary
+// service, instead of the backends defined by the ingress rules.
+type cookieRoute struct {
+	Cookie  string `json:"cookie"`
+	Value   string `json:"value"`
+	Service string `json:"service"`
+	Port    string `json:"port"`
+}
+
+// parse cookie route annotation
+func cookieRouteAnnotation(m *definitions.Metadata, logger *log.Entry) *cookieRoute {
+	val, ok := m.Annotations[skipperCookieRouteAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var cr cookieRoute
+	if err := json.Unmarshal([]byte(val), &cr); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperCookieRouteAnnotationKey, err)
+		return nil
+	}
+
+	if cr.Cookie == "" || cr.Service == "" || cr.Port == "" {
+		logger.Errorf("invalid %s annotation, cookie, service and port are required", skipperCookieRouteAnnotationKey)
+		return nil
+	}
+
+	return &cr
+}
+
+func (cr *cookieRoute) backendPort() definitions.BackendPortV1 {
+	if n, err := strconv.Atoi(cr.Port); err == nil {
+		return definitions.BackendPortV1{Number: n}
+	}
+
+	return definitions.BackendPortV1{Name: cr.Port}
+}
+
+func (cr *cookieRoute) predicate() *eskip.Predicate {
+	return &eskip.Predicate{
+		Name: predicates.CookieName,
+		Args: []interface{}{cr.Cookie, "^" + regexp.QuoteMeta(cr.Value) + "$"},
+	}
+}
+
+// addCookieRouteV1 creates a route to the canary service for the host and path
+// of the path rule, matching the configured cookie. Having the additional Cookie
+// predicate, the route takes precedence over the routes of the path rule, also
+// when they split the traffic between weighted backends.
+func (ing *ingress) addCookieRouteV1(ic ingressContext, host string, prule *definitions.PathRuleV1) error {
+	cr := ic.cookieRoute
+	return ing.addCanaryRouteV1(ic, host, prule, "cookie", cr.Service, cr.backendPort(), cr.predicate())
+}
@@ -0,0 +1,127 @@
+package kubernetes
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+// defaultBackendRoute is the route of the default backend of an ingress,
+// together with the owner ingress, its rule hosts and its forced status.
+type defaultBackendRoute struct {
+	route       *eskip.Route
+	owner       definitions.ResourceID
+	hosts       []string
+	forceStatus *forceStatus
+}
+
+// routes returns the route of the default backend, and, when the status of
+// the ingress is forced, its forced status route.
+func (d defaultBackendRoute) routes() []*eskip.Route {
+	if d.forceStatus == nil {
+		return []*eskip.Route{d.route}
+	}
+
+	return []*eskip.Route{d.route, d.forceStatus.route(d.route)}
+}
+
+func ingressRuleHosts(rules []*definitions.Rule) []string {
+	var hosts []string
+	for _, rule := range rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+
+	return hosts
+}
+
+func ingressRuleHostsV1(rules []*definitions.RuleV1) []string {
+	var hosts []string
+	for _, rule := range rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+
+	return hosts
+}
+
+// firstDefaultBackend returns the default backend of the ingress that comes
+// first by namespace and name.
+func firstDefaultBackend(d []defaultBackendRoute) []defaultBackendRoute {
+	if len(d) <= 1 {
+		return d
+	}
+
+	sort.Slice(d, func(i, j int) bool {
+		if d[i].owner.Namespace != d[j].owner.Namespace {
+			return d[i].owner.Namespace < d[j].owner.Namespace
+		}
+
+		return d[i].owner.Name < d[j].owner.Name
+	})
+
+	for _, di := range d[1:] {
+		log.Warnf("Ignoring the default backend of ingress %s/%s, conflicting with ingress %s/%s", di.owner.Namespace, di.owner.Name, d[0].owner.Namespace, d[0].owner.Name)
+	}
+
+	return d[:1]
+}
+
+// perHostDefaultBackends moves the default backends of the ingresses with
+// rules to the hosts of the rules, and returns the default backends of the
+// ingresses without rules, resolving their conflicts by the first-wins
+// policy.
+func (ing *ingress) perHostDefaultBackends(d []defaultBackendRoute, hostRoutes map[string][]*eskip.Route, routeOwners map[string]definitions.ResourceID) []defaultBackendRoute {
+	var global []defaultBackendRoute
+	for _, di := range d {
+		if len(di.hosts) == 0 {
+			global = append(global, di)
+			continue
+		}
+
+		for _, host := range di.hosts {
+			r := *di.route
+			r.Predicates = append([]*eskip.Predicate(nil), r.Predicates...)
+			r.Id = routeID(di.owner.Namespace, di.owner.Name, host, "", "")
+			r.HostRegexps = []string{createHostRxPort(ing.hostPortRx, host)}
+			dr := di
+			dr.route = &r
+			for _, ri := range dr.routes() {
+				hostRoutes[host] = append(hostRoutes[host], ri)
+				routeOwners[ri.Id] = di.owner
+			}
+		}
+	}
+
+	return firstDefaultBackend(global)
+}
+
+// resolveDefaultBackends applies the DefaultBackendConflictPolicy to the
+// default backends of the ingresses, and returns the ones to be used as
+// routes without host. With the per-host policy, the default backends of
+// the ingresses with rules are added to the host routes.
+func (ing *ingress) resolveDefaultBackends(d []defaultBackendRoute, hostRoutes map[string][]*eskip.Route, routeOwners map[string]definitions.ResourceID) []defaultBackendRoute {
+	switch ing.defaultBackendConflictPolicy {
+	case DefaultBackendConflictFirstWins:
+		return firstDefaultBackend(d)
+	case DefaultBackendConflictError:
+		if len(d) <= 1 {
+			return d
+		}
+
+		for _, di := range d {
+			log.Errorf("Ignoring the default backend of ingress %s/%s, conflicting default backends of %d ingresses", di.owner.Namespace, di.owner.Name, len(d))
+		}
+
+		return nil
+	case DefaultBackendConflictPerHost:
+		return ing.perHostDefaultBackends(d, hostRoutes, routeOwners)
+	default:
+		return d
+	}
+}
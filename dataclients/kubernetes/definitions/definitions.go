@@ -1,6 +1,8 @@
 package definitions
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"errors"
@@ -14,6 +16,7 @@ type Metadata struct {
 	Created     time.Time         `json:"creationTimestamp"`
 	Uid         string            `json:"uid"`
 	Annotations map[string]string `json:"annotations"`
+	Labels      map[string]string `json:"labels"`
 }
 
 func (meta *Metadata) ToResourceID() ResourceID {
@@ -37,3 +40,36 @@ func namespaceString(ns string) string {
 
 	return ns
 }
+
+type rawList struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// parseItemsLenient parses the items of a JSON list one by one, calling
+// parseItem with each. The items failing to parse are skipped, and the
+// returned errors describe them.
+func parseItemsLenient(d []byte, parseItem func(json.RawMessage) error) ([]error, error) {
+	var l rawList
+	if err := json.Unmarshal(d, &l); err != nil {
+		return nil, err
+	}
+
+	var diagnostics []error
+	for i, item := range l.Items {
+		if err := parseItem(item); err != nil {
+			var m struct {
+				Metadata *Metadata `json:"metadata"`
+			}
+
+			if json.Unmarshal(item, &m) == nil && m.Metadata != nil {
+				err = fmt.Errorf("item %d, %s/%s: %w", i, m.Metadata.Namespace, m.Metadata.Name, err)
+			} else {
+				err = fmt.Errorf("item %d: %w", i, err)
+			}
+
+			diagnostics = append(diagnostics, err)
+		}
+	}
+
+	return diagnostics, nil
+}
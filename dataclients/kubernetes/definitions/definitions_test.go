@@ -1,11 +1,41 @@
 package definitions_test
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/dataclients/kubernetes/kubernetestest"
 )
 
 func TestRouteGroupValidation(t *testing.T) {
 	kubernetestest.FixturesToTest(t, "testdata/validation")
 }
+
+func TestParseIngressV1JSONLenient(t *testing.T) {
+	d := []byte(`{"items": [
+		{"metadata": {"namespace": "foo", "name": "broken"}, "spec": {"rules": "not a list"}},
+		{"metadata": {"namespace": "foo", "name": "valid"}, "spec": {"rules": []}}
+	]}`)
+
+	if _, err := definitions.ParseIngressV1JSON(d); err == nil {
+		t.Fatal("failed to fail")
+	}
+
+	il, diagnostics, err := definitions.ParseIngressV1JSONLenient(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(il.Items) != 1 || il.Items[0].Metadata.Name != "valid" {
+		t.Errorf("expected only the valid item, got: %d", len(il.Items))
+	}
+
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Error(), "foo/broken") {
+		t.Errorf("expected a diagnostic of the broken item, got: %v", diagnostics)
+	}
+
+	if _, _, err := definitions.ParseIngressV1JSONLenient([]byte(`{"items": {}}`)); err == nil {
+		t.Error("failed to fail on a malformed list")
+	}
+}
@@ -83,6 +83,24 @@ func ParseIngressV1JSON(d []byte) (IngressV1List, error) {
 	return il, err
 }
 
+// ParseIngressV1JSONLenient parse JSON into an IngressV1List, skipping the
+// malformed items. The returned diagnostics describe the skipped items. It
+// fails only when the list itself is malformed.
+func ParseIngressV1JSONLenient(d []byte) (IngressV1List, []error, error) {
+	var il IngressV1List
+	diagnostics, err := parseItemsLenient(d, func(item json.RawMessage) error {
+		var i IngressV1Item
+		if err := json.Unmarshal(item, &i); err != nil {
+			return err
+		}
+
+		il.Items = append(il.Items, &i)
+		return nil
+	})
+
+	return il, diagnostics, err
+}
+
 // ParseIngressV1YAML parse YAML into an IngressV1List
 func ParseIngressV1YAML(d []byte) (IngressV1List, error) {
 	var il IngressV1List
@@ -119,6 +119,24 @@ func ParseIngressJSON(d []byte) (IngressList, error) {
 	return il, err
 }
 
+// ParseIngressJSONLenient parse JSON into an IngressList, skipping the
+// malformed items. The returned diagnostics describe the skipped items. It
+// fails only when the list itself is malformed.
+func ParseIngressJSONLenient(d []byte) (IngressList, []error, error) {
+	var il IngressList
+	diagnostics, err := parseItemsLenient(d, func(item json.RawMessage) error {
+		var i IngressItem
+		if err := json.Unmarshal(item, &i); err != nil {
+			return err
+		}
+
+		il.Items = append(il.Items, &i)
+		return nil
+	})
+
+	return il, diagnostics, err
+}
+
 // ParseIngressYAML parse YAML into an IngressList
 func ParseIngressYAML(d []byte) (IngressList, error) {
 	var il IngressList
@@ -1,9 +1,9 @@
 package kubernetes
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
 )
 
@@ -11,18 +11,22 @@ func eastWestRouteID(rid string) string {
 	return "kubeew" + rid[len(ingressRouteIDPrefix):]
 }
 
-func createEastWestRouteIng(eastWestDomain, name, ns string, r *eskip.Route) *eskip.Route {
-	if strings.HasPrefix(r.Id, "kubeew") || ns == "" || name == "" {
+func isEastWestRouteID(rid string) bool {
+	return strings.HasPrefix(rid, "kubeew")
+}
+
+func createEastWestRouteIng(ewHosts eastWestHosts, eastWestDomain, name, ns string, r *eskip.Route) *eskip.Route {
+	if isEastWestRouteID(r.Id) || ns == "" || name == "" {
 		return nil
 	}
 	ewR := *r
-	ewR.HostRegexps = []string{createHostRx(name + "." + ns + "." + eastWestDomain)}
+	ewR.HostRegexps = []string{ewHosts.hostRx(name, ns, eastWestDomain)}
 	ewR.Id = eastWestRouteID(r.Id)
 	return &ewR
 }
 
-func createEastWestRouteRG(name, ns, postfix string, r *eskip.Route) *eskip.Route {
-	hostRx := createHostRx(fmt.Sprintf("%s.%s.%s", name, ns, postfix))
+func createEastWestRouteRG(ewHosts eastWestHosts, name, ns, postfix string, r *eskip.Route) *eskip.Route {
+	hostRx := ewHosts.hostRx(name, ns, postfix)
 
 	ewr := eskip.Copy(r)
 	ewr.Id = eastWestRouteID(ewr.Id)
@@ -57,3 +61,57 @@ func applyEastWestRangePredicates(routes []*eskip.Route, predicates []*eskip.Pre
 		route.Predicates = append(route.Predicates, predicates...)
 	}
 }
+
+// dedupEastWestRoute tells whether an east-west route needs to be added. The
+// east-west routes of different ingresses can have the same route ID, e.g.
+// when the same ingress is listed twice during an update, or the names of the
+// ingresses differ only in non-word characters. Of these, the route of the
+// ingress with the lowest namespace and name is kept, independent of the
+// order of the ingresses, and the other route is removed.
+func (ic *ingressContext) dedupEastWestRoute(r *eskip.Route) bool {
+	owner, ok := ic.routeOwners[r.Id]
+	if !ok {
+		return true
+	}
+
+	current := ic.resourceID()
+	for host, routes := range ic.hostRoutes {
+		for i, ri := range routes {
+			if ri == nil || ri.Id != r.Id {
+				continue
+			}
+
+			if owner == current && ri.String() == r.String() {
+				return false
+			}
+
+			keep := owner
+			replace := resourceIDLess(current, owner) || current == owner && r.String() < ri.String()
+			if replace {
+				keep = current
+			}
+
+			ic.logger.Warnf(
+				"Duplicate east-west route %s of ingresses %s/%s and %s/%s, using the route of %s/%s",
+				r.Id, owner.Namespace, owner.Name, current.Namespace, current.Name, keep.Namespace, keep.Name,
+			)
+
+			if !replace {
+				return false
+			}
+
+			ic.hostRoutes[host] = append(routes[:i:i], routes[i+1:]...)
+			return true
+		}
+	}
+
+	return true
+}
+
+func resourceIDLess(a, b definitions.ResourceID) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+
+	return a.Name < b.Name
+}
@@ -9,6 +9,7 @@ import (
 
 func TestCreateEastWestRouteIng(t *testing.T) {
 	type args struct {
+		order          EastWestHostOrder
 		eastWestDomain string
 		hostname       string
 		namespace      string
@@ -71,10 +72,27 @@ func TestCreateEastWestRouteIng(t *testing.T) {
 				HostRegexps: []string{"^(serviceA[.]default[.]cluster[.]local[.]?(:[0-9]+)?)$"},
 			},
 		},
+		{
+			name: "return the route with the namespace first in the HostRegexp",
+			args: args{
+				order:          EastWestNamespaceFirst,
+				eastWestDomain: "cluster.local",
+				hostname:       "serviceA",
+				namespace:      "default",
+				route: &eskip.Route{
+					Id:          "kube_foo__qux__www3_example_org___a_path__bar",
+					HostRegexps: []string{"www2[.]example[.]org"},
+				},
+			},
+			want: &eskip.Route{
+				Id:          "kubeew_foo__qux__www3_example_org___a_path__bar",
+				HostRegexps: []string{"^(default[.]serviceA[.]cluster[.]local[.]?(:[0-9]+)?)$"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			eastWestRouteIng := createEastWestRouteIng(tt.args.eastWestDomain, tt.args.hostname, tt.args.namespace, tt.args.route)
+			eastWestRouteIng := createEastWestRouteIng(newEastWestHosts(Options{EastWestHostOrder: tt.args.order}), tt.args.eastWestDomain, tt.args.hostname, tt.args.namespace, tt.args.route)
 			if !reflect.DeepEqual(eastWestRouteIng, tt.want) {
 				t.Errorf("createEastWestRouteIng() = %v, want %v", eastWestRouteIng, tt.want)
 			}
@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+)
+
+const skipperEndpointConditionAnnotationKey = "zalando.org/skipper-endpoint-condition"
+
+// endpointCondition returns the pod condition type set by the
+// zalando.org/skipper-endpoint-condition annotation of the service, e.g. the
+// condition of a custom readiness gate. When set, only those endpoints are
+// used as backends, whose pod has the condition with the status "True".
+func endpointCondition(svc *service) string {
+	if svc == nil || svc.Meta == nil {
+		return ""
+	}
+
+	return svc.Meta.Annotations[skipperEndpointConditionAnnotationKey]
+}
+
+// endpointConditionNamespaces adds the namespaces of the services with an
+// endpoint condition annotation.
+func endpointConditionNamespaces(m map[string]bool, services map[definitions.ResourceID]*service) {
+	for id, svc := range services {
+		if endpointCondition(svc) != "" {
+			m[id.Namespace] = true
+		}
+	}
+}
+
+// selectConditionPods returns the endpoint with only those addresses, whose
+// pod has the condition set to "True".
+func (ep endpoint) selectConditionPods(condition string, pods map[definitions.ResourceID]*pod) endpoint {
+	return ep.selectPods(pods, func(p *pod) bool { return p.condition(condition) })
+}
@@ -0,0 +1,110 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+)
+
+const skipperEndpointSelectorAnnotationKey = "zalando.org/skipper-endpoint-selector"
+
+// endpointSelector contains the labels that the pods of the endpoints need to
+// carry, to be used as the backends of an ingress. It is defined by the
+// zalando.org/skipper-endpoint-selector annotation, e.g. "version=canary".
+type endpointSelector map[string]string
+
+// parseEndpointSelector parses a comma separated list of equality based label
+// requirements, e.g. "application=foo,version=canary".
+func parseEndpointSelector(s string) (endpointSelector, error) {
+	sel := make(endpointSelector)
+	for _, req := range strings.Split(s, ",") {
+		kv := strings.SplitN(req, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid label requirement: %q", req)
+		}
+
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if key == "" {
+			return nil, fmt.Errorf("missing label name: %q", req)
+		}
+
+		sel[key] = value
+	}
+
+	return sel, nil
+}
+
+// endpointSelectorAnnotation returns the endpoint selector of an ingress, or
+// nil, when the annotation is not set or invalid.
+func endpointSelectorAnnotation(m *definitions.Metadata, logger *log.Entry) endpointSelector {
+	val, ok := m.Annotations[skipperEndpointSelectorAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	sel, err := parseEndpointSelector(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation: %v", skipperEndpointSelectorAnnotationKey, err)
+		return nil
+	}
+
+	return sel
+}
+
+func (sel endpointSelector) matches(labels map[string]string) bool {
+	for k, v := range sel {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the canonical form of the selector, used as part of the
+// endpoint cache key.
+func (sel endpointSelector) String() string {
+	reqs := make([]string, 0, len(sel))
+	for k, v := range sel {
+		reqs = append(reqs, k+"="+v)
+	}
+
+	sort.Strings(reqs)
+	return strings.Join(reqs, ",")
+}
+
+// hasEndpointSelector tells whether the ingress has an endpoint selector
+// annotation, requiring the labels of its pods.
+func hasEndpointSelector(m *definitions.Metadata) bool {
+	if m == nil {
+		return false
+	}
+
+	_, ok := m.Annotations[skipperEndpointSelectorAnnotationKey]
+	return ok
+}
+
+// endpointSelectorNamespaces adds the namespaces of the ingresses with an
+// endpoint selector annotation.
+func endpointSelectorNamespaces(m map[string]bool, ingresses []*definitions.IngressItem, ingressesV1 []*definitions.IngressV1Item) {
+	for _, i := range ingresses {
+		if hasEndpointSelector(i.Metadata) {
+			m[namespaceString(i.Metadata.Namespace)] = true
+		}
+	}
+
+	for _, i := range ingressesV1 {
+		if hasEndpointSelector(i.Metadata) {
+			m[namespaceString(i.Metadata.Namespace)] = true
+		}
+	}
+}
+
+// matchesPod tells whether the labels of the pod match the selector.
+func (sel endpointSelector) matchesPod(p *pod) bool {
+	return p.Meta != nil && sel.matches(p.Meta.Labels)
+}
@@ -0,0 +1,93 @@
+package kubernetes
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/loadbalancer"
+)
+
+// EndpointSubsetting configures each skipper replica to use only a stable
+// subset of the endpoints of the load balanced routes, e.g. to reduce the
+// number of connections to the backends in large fleets. The subsets are
+// selected with rendezvous hashing, so that the subset of a replica changes
+// only minimally when the endpoints change, and the endpoints are evenly
+// spread between the replicas.
+type EndpointSubsetting struct {
+	// ReplicaID identifies the skipper replica, e.g. the name of the pod.
+	ReplicaID string
+
+	// SubsetSize is the maximum number of endpoints used by a replica for a
+	// route. Subsetting is disabled when zero.
+	SubsetSize int
+}
+
+// subsetEndpoints reduces the endpoints of the load balanced routes to the
+// subset selected for the replica. The routes with the consistentHash
+// algorithm, e.g. the sticky session routes, are left unchanged, because the
+// replicas need to map the same keys to the same endpoints.
+func subsetEndpoints(r []*eskip.Route, s EndpointSubsetting) {
+	consistentHash := loadbalancer.ConsistentHash.String()
+	for _, ri := range r {
+		if ri.BackendType != eskip.LBBackend || ri.LBAlgorithm == consistentHash {
+			continue
+		}
+
+		ri.LBEndpoints = endpointSubset(ri.LBEndpoints, s)
+	}
+}
+
+func endpointScore(replicaID, endpoint string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(replicaID))
+	h.Write([]byte{0})
+	h.Write([]byte(endpoint))
+	return h.Sum32()
+}
+
+// endpointSubset returns the endpoints with the highest scores for the
+// replica. The endpoints repeated for weighting are kept together with their
+// repetitions, and the original order is preserved.
+func endpointSubset(endpoints []string, s EndpointSubsetting) []string {
+	unique := make([]string, 0, len(endpoints))
+	seen := make(map[string]bool)
+	for _, ep := range endpoints {
+		if !seen[ep] {
+			seen[ep] = true
+			unique = append(unique, ep)
+		}
+	}
+
+	if len(unique) <= s.SubsetSize {
+		return endpoints
+	}
+
+	scores := make(map[string]uint32, len(unique))
+	for _, ep := range unique {
+		scores[ep] = endpointScore(s.ReplicaID, ep)
+	}
+
+	sort.Slice(unique, func(i, j int) bool {
+		si, sj := scores[unique[i]], scores[unique[j]]
+		if si != sj {
+			return si > sj
+		}
+
+		return unique[i] < unique[j]
+	})
+
+	selected := make(map[string]bool, s.SubsetSize)
+	for _, ep := range unique[:s.SubsetSize] {
+		selected[ep] = true
+	}
+
+	subset := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if selected[ep] {
+			subset = append(subset, ep)
+		}
+	}
+
+	return subset
+}
@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+func TestEndpointSubsetting(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+		testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+		),
+	}}, &endpointList{
+		Items: testEndpoints("foo", "bar", "1.1.1", 10, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	loadSubset := func(replicaID string) []string {
+		dc, err := New(Options{
+			KubernetesURL:      api.server.URL,
+			EndpointSubsetting: EndpointSubsetting{ReplicaID: replicaID, SubsetSize: 6},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer dc.Close()
+
+		r, err := dc.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, ri := range r {
+			if ri.BackendType == eskip.LBBackend {
+				return ri.LBEndpoints
+			}
+		}
+
+		t.Fatal("load balanced route not found")
+		return nil
+	}
+
+	a, b := loadSubset("skipper-a"), loadSubset("skipper-b")
+	for _, subset := range [][]string{a, b} {
+		if len(subset) != 6 {
+			t.Fatalf("expected a subset of 6 endpoints, got: %v", subset)
+		}
+	}
+
+	if !reflect.DeepEqual(a, loadSubset("skipper-a")) || !reflect.DeepEqual(b, loadSubset("skipper-b")) {
+		t.Error("expected the subsets to be stable")
+	}
+
+	if reflect.DeepEqual(a, b) {
+		t.Errorf("expected the subsets of the replicas to differ, got: %v", a)
+	}
+
+	inA := make(map[string]bool)
+	for _, ep := range a {
+		inA[ep] = true
+	}
+
+	var overlap int
+	for _, ep := range b {
+		if inA[ep] {
+			overlap++
+		}
+	}
+
+	if overlap == 0 {
+		t.Errorf("expected the subsets to overlap, got: %v and %v", a, b)
+	}
+}
+
+func TestEndpointSubsetKeepsWeights(t *testing.T) {
+	endpoints := []string{"http://10.0.0.1:80", "http://10.0.0.1:80", "http://10.0.0.2:80", "http://10.0.0.3:80"}
+	subset := endpointSubset(endpoints, EndpointSubsetting{ReplicaID: "skipper-a", SubsetSize: 2})
+
+	unique := make(map[string]int)
+	for _, ep := range subset {
+		unique[ep]++
+	}
+
+	if len(unique) != 2 {
+		t.Fatalf("expected 2 distinct endpoints, got: %v", subset)
+	}
+
+	if n, ok := unique["http://10.0.0.1:80"]; ok && n != 2 {
+		t.Errorf("expected the repetitions of the weighted endpoint to be kept, got: %v", subset)
+	}
+
+	if got := endpointSubset(endpoints, EndpointSubsetting{ReplicaID: "skipper-a", SubsetSize: 3}); !reflect.DeepEqual(got, endpoints) {
+		t.Errorf("expected all the endpoints when the subset size is not smaller, got: %v", got)
+	}
+}
+
+func TestEndpointSubsettingSkipsConsistentHash(t *testing.T) {
+	var endpoints []string
+	for i := 1; i <= 10; i++ {
+		endpoints = append(endpoints, fmt.Sprintf("http://10.0.0.%d:80", i))
+	}
+
+	roundRobin := &eskip.Route{Id: "roundRobin", BackendType: eskip.LBBackend, LBAlgorithm: "roundRobin", LBEndpoints: endpoints}
+	consistentHash := &eskip.Route{Id: "consistentHash", BackendType: eskip.LBBackend, LBAlgorithm: "consistentHash", LBEndpoints: endpoints}
+	subsetEndpoints([]*eskip.Route{roundRobin, consistentHash}, EndpointSubsetting{ReplicaID: "skipper-a", SubsetSize: 3})
+
+	if len(roundRobin.LBEndpoints) != 3 {
+		t.Errorf("expected a subset of 3 endpoints, got: %v", roundRobin.LBEndpoints)
+	}
+
+	if !reflect.DeepEqual(consistentHash.LBEndpoints, endpoints) {
+		t.Errorf("expected all the endpoints of the consistent hash route, got: %v", consistentHash.LBEndpoints)
+	}
+}
+
+func TestEndpointSubsettingStickySession(t *testing.T) {
+	ing := testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+		testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+	)
+	ing.Metadata.Annotations[skipperStickySessionAnnotationKey] = `{"cookie": "session"}`
+
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+		Items: testEndpoints("foo", "bar", "1.1.1", 10, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	dc, err := New(Options{
+		KubernetesURL:      api.server.URL,
+		EndpointSubsetting: EndpointSubsetting{ReplicaID: "skipper-a", SubsetSize: 6},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	r, err := dc.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sticky, fallback int
+	for _, ri := range r {
+		if ri.BackendType != eskip.LBBackend {
+			continue
+		}
+
+		if ri.LBAlgorithm == "consistentHash" {
+			sticky++
+			if len(ri.LBEndpoints) != 10 {
+				t.Errorf("expected all the endpoints for the sticky session route, got: %v", ri.LBEndpoints)
+			}
+		} else {
+			fallback++
+			if len(ri.LBEndpoints) != 6 {
+				t.Errorf("expected a subset of 6 endpoints for the fallback route, got: %v", ri.LBEndpoints)
+			}
+		}
+	}
+
+	if sticky != 1 || fallback != 1 {
+		t.Errorf("expected a sticky session and a fallback route, got: %d and %d", sticky, fallback)
+	}
+}
+
+func TestEndpointSubsettingOptions(t *testing.T) {
+	for _, s := range []EndpointSubsetting{
+		{SubsetSize: -1, ReplicaID: "skipper-a"},
+		{SubsetSize: 3},
+	} {
+		if _, err := New(Options{EndpointSubsetting: s}); err == nil {
+			t.Errorf("expected error for %+v", s)
+		}
+	}
+}
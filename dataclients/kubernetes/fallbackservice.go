@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+const skipperFallbackServiceAnnotationKey = "zalando.org/skipper-fallback-service"
+
+// fallbackService is the configuration of the
+// zalando.org/skipper-fallback-service annotation. The paths of the ingress,
+// whose service has no endpoints, are routed to the endpoints of the fallback
+// service, instead of responding with 502.
+type fallbackService struct {
+	Name string                  `json:"name"`
+	Port definitions.BackendPort `json:"port"`
+}
+
+// parse fallback service annotation
+func fallbackServiceAnnotation(m *definitions.Metadata, logger *log.Entry) *fallbackService {
+	val, ok := m.Annotations[skipperFallbackServiceAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var fs fallbackService
+	if err := json.Unmarshal([]byte(val), &fs); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperFallbackServiceAnnotationKey, err)
+		return nil
+	}
+
+	if fs.Name == "" || fs.Port.Value == nil {
+		logger.Errorf("invalid %s annotation, service name and port are required", skipperFallbackServiceAnnotationKey)
+		return nil
+	}
+
+	return &fs
+}
+
+// endpoints returns the endpoints of the fallback service, from the namespace
+// of the ingress.
+func (fs *fallbackService) endpoints(state *clusterState, m *definitions.Metadata, sel endpointSelector) []string {
+	svc, err := state.getService(m.Namespace, fs.Name)
+	if err != nil {
+		log.Debugf("Fallback service %s/%s not found", m.Namespace, fs.Name)
+		return nil
+	}
+
+	if svc.Spec.Type == "ExternalName" {
+		log.Debugf("Fallback service %s/%s of type ExternalName is not supported", m.Namespace, fs.Name)
+		return nil
+	}
+
+	servicePort, err := svc.getServicePort(fs.Port)
+	if err != nil {
+		log.Debugf("Fallback service %s/%s port %s not found", m.Namespace, fs.Name, fs.Port)
+		return nil
+	}
+
+	protocol := "http"
+	if p, ok := m.Annotations[skipperBackendProtocolAnnotationKey]; ok {
+		protocol = p
+	}
+
+	return state.getEndpointsByService(m.Namespace, fs.Name, protocol, servicePort, sel)
+}
+
+// apply routes the shunt route of a service without endpoints to the
+// fallback service. The route is left unchanged when the fallback service
+// has no endpoints either.
+func (fs *fallbackService) apply(r *eskip.Route, state *clusterState, m *definitions.Metadata, sel endpointSelector, defaultLBAlgorithm string) {
+	if r.BackendType != eskip.ShuntBackend {
+		return
+	}
+
+	eps := fs.endpoints(state, m, sel)
+	if len(eps) == 0 {
+		return
+	}
+
+	log.Debugf("Routing %s to the fallback service %s/%s", r.Id, m.Namespace, fs.Name)
+	r.Filters = nil
+	if len(eps) == 1 {
+		r.BackendType = eskip.NetworkBackend
+		r.Backend = eps[0]
+		return
+	}
+
+	r.BackendType = eskip.LBBackend
+	r.LBEndpoints = eps
+	r.LBAlgorithm = getLoadBalancerAlgorithm(m, defaultLBAlgorithm)
+}
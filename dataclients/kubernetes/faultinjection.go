@@ -0,0 +1,68 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperFaultInjectionAnnotationKey = "zalando.org/skipper-fault-injection"
+
+// faultInjection is the configuration of the zalando.org/skipper-fault-injection
+// annotation. The configured ratio of the requests is responded with the
+// configured status, without being proxied to the backend.
+type faultInjection struct {
+	Ratio  float64 `json:"ratio"`
+	Status int     `json:"status"`
+}
+
+// parse fault injection annotation
+func faultInjectionAnnotation(m *definitions.Metadata, logger *log.Entry) *faultInjection {
+	val, ok := m.Annotations[skipperFaultInjectionAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var fi faultInjection
+	if err := json.Unmarshal([]byte(val), &fi); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperFaultInjectionAnnotationKey, err)
+		return nil
+	}
+
+	if fi.Ratio <= 0 || fi.Ratio > 1 {
+		logger.Errorf("invalid %s annotation, ratio must be in (0, 1]: %v", skipperFaultInjectionAnnotationKey, fi.Ratio)
+		return nil
+	}
+
+	if fi.Status < 400 || fi.Status > 599 {
+		logger.Errorf("invalid %s annotation, status must be an error status: %d", skipperFaultInjectionAnnotationKey, fi.Status)
+		return nil
+	}
+
+	return &fi
+}
+
+// route creates the fault injection route of a path, from the route of the
+// path that doesn't split the traffic between the backends. Besides the
+// Traffic predicate with the configured ratio, the fault injection route gets
+// a True predicate for each predicate used to split the traffic of the path,
+// so that it precedes all the backend routes of the path, and the ratio
+// applies to all the requests of the path.
+func (fi *faultInjection) route(r *eskip.Route, splitPredicates int) *eskip.Route {
+	p := append([]*eskip.Predicate{{
+		Name: predicates.TrafficName,
+		Args: []interface{}{fi.Ratio},
+	}}, truePredicates(splitPredicates)...)
+
+	fr := companionRoute(r, "fault_injection", 0, p...)
+	shuntCompanionRoute(fr, &eskip.Filter{
+		Name: filters.StatusName,
+		Args: []interface{}{float64(fi.Status)},
+	})
+
+	return fr
+}
@@ -0,0 +1,70 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	skipperForceStatusAnnotationKey = "zalando.org/skipper-force-status"
+
+	// the weight of the forced status routes, the same as the one of the
+	// HTTPS redirect routes
+	forceStatusWeight = 1000
+)
+
+// forceStatus is the configuration of the zalando.org/skipper-force-status
+// annotation, e.g. for maintenance pages. All the requests of the ingress
+// are responded with the configured status and body, without being proxied
+// to the backend.
+type forceStatus struct {
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+// parse force status annotation
+func forceStatusAnnotation(m *definitions.Metadata, logger *log.Entry) *forceStatus {
+	val, ok := m.Annotations[skipperForceStatusAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var fs forceStatus
+	if err := json.Unmarshal([]byte(val), &fs); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperForceStatusAnnotationKey, err)
+		return nil
+	}
+
+	if fs.Status < 200 || fs.Status > 599 {
+		logger.Errorf("invalid %s annotation, status must be between 200 and 599: %d", skipperForceStatusAnnotationKey, fs.Status)
+		return nil
+	}
+
+	return &fs
+}
+
+// route creates the forced status route for a route of the ingress. With the
+// weight of the forced status routes, it precedes the route, and all the other
+// companion routes, e.g. the ones of the sticky sessions or the fault
+// injection, so that no request of the ingress reaches the backend.
+func (fs *forceStatus) route(r *eskip.Route) *eskip.Route {
+	f := []*eskip.Filter{{
+		Name: filters.StatusName,
+		Args: []interface{}{float64(fs.Status)},
+	}}
+
+	if fs.Body != "" {
+		f = append(f, &eskip.Filter{
+			Name: filters.InlineContentName,
+			Args: []interface{}{fs.Body},
+		})
+	}
+
+	fr := companionRoute(r, "force_status", forceStatusWeight)
+	shuntCompanionRoute(fr, f...)
+	return fr
+}
@@ -1,14 +1,54 @@
 package kubernetes
 
 import (
+	"fmt"
+	"net"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/zalando/skipper/eskip"
 )
 
+const (
+	hostMatchPortAny  = "any"
+	hostMatchPortNone = "none"
+	anyPortRx         = "(:[0-9]+)?"
+	trailingDotRx     = "[.]?"
+)
+
+// hostPortRx returns the trailing dot and port matching part of the host
+// regexps for the HostMatchPort and HostTrailingDot options.
+func hostPortRx(matchPort string, trailingDot HostTrailingDot) (string, error) {
+	var dotRx string
+	switch trailingDot {
+	case HostTrailingDotAllow, HostTrailingDotNormalize:
+		dotRx = trailingDotRx
+	case HostTrailingDotReject:
+	default:
+		return "", fmt.Errorf("invalid host trailing dot option: %d", trailingDot)
+	}
+
+	switch matchPort {
+	case "", hostMatchPortAny:
+		return dotRx + anyPortRx, nil
+	case hostMatchPortNone:
+		return dotRx, nil
+	default:
+		if port, err := strconv.Atoi(matchPort); err != nil || port <= 0 || port > 65535 {
+			return "", fmt.Errorf("invalid host match port: %s", matchPort)
+		}
+
+		return dotRx + "(:" + matchPort + ")?", nil
+	}
+}
+
 func createHostRx(hosts ...string) string {
+	return createHostRxPort(trailingDotRx+anyPortRx, hosts...)
+}
+
+func createHostRxPort(portRx string, hosts ...string) string {
 	if len(hosts) == 0 {
 		return ""
 	}
@@ -16,19 +56,38 @@ func createHostRx(hosts ...string) string {
 	hrx := make([]string, len(hosts))
 	for i, host := range hosts {
 		// trailing dots and port are not allowed in kube
-		// ingress spec, so we can append optional setting
-		// without check
-		hrx[i] = strings.Replace(host, ".", "[.]", -1) + "[.]?(:[0-9]+)?"
+		// ingress spec, so we can append the optional
+		// trailing dot and port without check, after
+		// removing the port from the invalid hosts
+		host, _ = stripHostPort(host)
+		hrx[i] = strings.Replace(host, ".", "[.]", -1) + portRx
 	}
 
 	return "^(" + strings.Join(hrx, "|") + ")$"
 }
 
+// stripHostPort removes the port from a host, because the hosts in the
+// ingress spec must not contain a port, and the regexps created from them
+// would not match the requests otherwise. It tells whether the host had a
+// port.
+func stripHostPort(host string) (string, bool) {
+	h, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, false
+	}
+
+	if _, err := strconv.Atoi(port); err != nil {
+		return host, false
+	}
+
+	return h, true
+}
+
 // hostCatchAllRoutes creates catch-all routes for those hosts that only have routes with
 // a Host predicate and at least one additional predicate.
 //
 // currently only used for RouteGroups
-func hostCatchAllRoutes(hostRoutes map[string][]*eskip.Route, createID func(string) string) []*eskip.Route {
+func hostCatchAllRoutes(hostRoutes map[string][]*eskip.Route, portRx string, createID func(string) string) []*eskip.Route {
 	var catchAll []*eskip.Route
 	for h, r := range hostRoutes {
 		var hasHostOnlyRoute bool
@@ -53,7 +112,7 @@ func hostCatchAllRoutes(hostRoutes map[string][]*eskip.Route, createID func(stri
 				Id: createID(h),
 				Predicates: []*eskip.Predicate{{
 					Name: "Host",
-					Args: []interface{}{createHostRx(h)},
+					Args: []interface{}{createHostRxPort(portRx, h)},
 				}},
 				BackendType: eskip.ShuntBackend,
 			})
@@ -73,6 +132,30 @@ func isExternalDomainAllowed(allowedDomains []*regexp.Regexp, domain string) boo
 	return false
 }
 
+// cloud metadata endpoints, that are not covered by the loopback and
+// link-local address ranges
+var metadataExternalNames = map[string]bool{
+	"fd00:ec2::254":            true,
+	"metadata.google.internal": true,
+}
+
+// isLocalExternalName tells whether an external name points to the loopback,
+// link-local or unspecified addresses, or to a cloud metadata endpoint.
+func isLocalExternalName(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") || metadataExternalNames[name] {
+		return true
+	}
+
+	ip := net.ParseIP(strings.Trim(name, "[]"))
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() ||
+		metadataExternalNames[ip.String()]
+}
+
 func isExternalAddressAllowed(allowedDomains []*regexp.Regexp, address string) bool {
 	u, err := url.Parse(address)
 	if err != nil {
@@ -0,0 +1,169 @@
+package kubernetes
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestHostMatchPort(t *testing.T) {
+	for _, test := range []struct {
+		matchPort string
+		fail      bool
+		matches   []string
+		rejects   []string
+	}{{
+		matchPort: "",
+		matches:   []string{"www.example.org", "www.example.org:8080", "www.example.org:8443"},
+	}, {
+		matchPort: "any",
+		matches:   []string{"www.example.org", "www.example.org:8080", "www.example.org:8443"},
+	}, {
+		matchPort: "none",
+		matches:   []string{"www.example.org", "www.example.org."},
+		rejects:   []string{"www.example.org:8080", "www.example.org:8443"},
+	}, {
+		matchPort: "8443",
+		matches:   []string{"www.example.org", "www.example.org:8443"},
+		rejects:   []string{"www.example.org:8080", "www.example.org:84430"},
+	}, {
+		matchPort: "http",
+		fail:      true,
+	}, {
+		matchPort: "0",
+		fail:      true,
+	}} {
+		t.Run(test.matchPort, func(t *testing.T) {
+			portRx, err := hostPortRx(test.matchPort, HostTrailingDotAllow)
+			if test.fail {
+				if err == nil {
+					t.Fatal("failed to fail")
+				}
+
+				if _, err := New(Options{HostMatchPort: test.matchPort}); err == nil {
+					t.Fatal("failed to fail creating the client")
+				}
+
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			rx := regexp.MustCompile(createHostRxPort(portRx, "www.example.org"))
+			for _, h := range test.matches {
+				if !rx.MatchString(h) {
+					t.Errorf("expected %s to match %s", h, rx)
+				}
+			}
+
+			for _, h := range test.rejects {
+				if rx.MatchString(h) {
+					t.Errorf("expected %s not to match %s", h, rx)
+				}
+			}
+		})
+	}
+}
+
+func TestHostTrailingDot(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		trailingDot HostTrailingDot
+		matches     []string
+		rejects     []string
+	}{{
+		title:       "allow",
+		trailingDot: HostTrailingDotAllow,
+		matches:     []string{"www.example.org", "www.example.org.", "www.example.org.:8080"},
+	}, {
+		title:       "reject",
+		trailingDot: HostTrailingDotReject,
+		matches:     []string{"www.example.org", "www.example.org:8080"},
+		rejects:     []string{"www.example.org.", "www.example.org.:8080"},
+	}, {
+		title:       "normalize",
+		trailingDot: HostTrailingDotNormalize,
+		matches:     []string{"www.example.org", "www.example.org.", "www.example.org.:8080"},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			portRx, err := hostPortRx("", test.trailingDot)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rx := regexp.MustCompile(createHostRxPort(portRx, "www.example.org"))
+			for _, h := range test.matches {
+				if !rx.MatchString(h) {
+					t.Errorf("expected %s to match %s", h, rx)
+				}
+			}
+
+			for _, h := range test.rejects {
+				if rx.MatchString(h) {
+					t.Errorf("expected %s not to match %s", h, rx)
+				}
+			}
+		})
+	}
+
+	if _, err := New(Options{HostTrailingDot: HostTrailingDot(42)}); err == nil {
+		t.Error("failed to fail creating the client with an invalid trailing dot option")
+	}
+}
+
+func TestIsLocalExternalName(t *testing.T) {
+	for _, name := range []string{
+		"localhost",
+		"LOCALHOST.",
+		"foo.localhost",
+		"127.0.0.1",
+		"127.1.2.3",
+		"::1",
+		"[::1]",
+		"0.0.0.0",
+		"169.254.169.254",
+		"fe80::1",
+		"fd00:ec2::254",
+		"metadata.google.internal",
+	} {
+		if !isLocalExternalName(name) {
+			t.Errorf("expected %s to be local", name)
+		}
+	}
+
+	for _, name := range []string{
+		"example.org",
+		"localhost.example.org",
+		"10.0.0.1",
+		"8.8.8.8",
+		"2001:db8::1",
+	} {
+		if isLocalExternalName(name) {
+			t.Errorf("expected %s not to be local", name)
+		}
+	}
+}
+
+func TestHostWithPort(t *testing.T) {
+	for _, host := range []string{"www.example.org:8443", "www.example.org:80"} {
+		t.Run(host, func(t *testing.T) {
+			rx := regexp.MustCompile(createHostRx(host))
+			for _, h := range []string{"www.example.org", "www.example.org:8443", "www.example.org.:8080"} {
+				if !rx.MatchString(h) {
+					t.Errorf("expected %s to match %s", h, rx)
+				}
+			}
+
+			if rx.MatchString("www.example.org:8443:8443") {
+				t.Errorf("expected the host with duplicate port not to match %s", rx)
+			}
+		})
+	}
+
+	if h, ok := stripHostPort("www.example.org:8443"); !ok || h != "www.example.org" {
+		t.Errorf("expected the port to be stripped, got: %s", h)
+	}
+
+	if h, ok := stripHostPort("www.example.org:http"); ok || h != "www.example.org:http" {
+		t.Errorf("expected the host with a non-numeric port to be kept, got: %s", h)
+	}
+}
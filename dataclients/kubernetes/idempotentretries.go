@@ -0,0 +1,49 @@
+package kubernetes
+
+import (
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperIdempotentRetriesAnnotationKey = "zalando.org/skipper-idempotent-retries"
+
+// the methods, whose failed backend requests are not retried, when the
+// retries are limited to the idempotent methods
+var nonIdempotentMethods = []interface{}{"POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
+// parse idempotent retries annotation
+func idempotentRetriesAnnotation(m *definitions.Metadata, logger *log.Entry) bool {
+	val, ok := m.Annotations[skipperIdempotentRetriesAnnotationKey]
+	if !ok {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, boolean expected: %s", skipperIdempotentRetriesAnnotationKey, val)
+		return false
+	}
+
+	return enabled
+}
+
+// nonIdempotentRoute creates the route disabling the retries for the requests
+// with the methods other than GET and HEAD. The Methods predicate makes it
+// more specific than the original route, which then handles only the GET and
+// HEAD requests, retried as usual.
+func nonIdempotentRoute(r *eskip.Route) *eskip.Route {
+	args := make([]interface{}, len(nonIdempotentMethods))
+	copy(args, nonIdempotentMethods)
+	nr := companionRoute(r, "non_idempotent", 0, &eskip.Predicate{
+		Name: predicates.MethodsName,
+		Args: args,
+	})
+
+	nr.Filters = append(nr.Filters, &eskip.Filter{Name: filters.DisableRetryName})
+	return nr
+}
@@ -6,31 +6,49 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/cookie"
 	"github.com/zalando/skipper/predicates"
 	"github.com/zalando/skipper/secrets/certregistry"
 )
 
 const (
-	ingressRouteIDPrefix                = "kube"
-	backendWeightsAnnotationKey         = "zalando.org/backend-weights"
-	ratelimitAnnotationKey              = "zalando.org/ratelimit"
-	skipperfilterAnnotationKey          = "zalando.org/skipper-filter"
-	skipperpredicateAnnotationKey       = "zalando.org/skipper-predicate"
-	skipperRoutesAnnotationKey          = "zalando.org/skipper-routes"
-	skipperLoadBalancerAnnotationKey    = "zalando.org/skipper-loadbalancer"
-	skipperBackendProtocolAnnotationKey = "zalando.org/skipper-backend-protocol"
-	pathModeAnnotationKey               = "zalando.org/skipper-ingress-path-mode"
-	ingressOriginName                   = "ingress"
-	tlsSecretType                       = "kubernetes.io/tls"
-	tlsSecretDataCrt                    = "tls.crt"
-	tlsSecretDataKey                    = "tls.key"
+	ingressRouteIDPrefix                     = "kube"
+	backendWeightsAnnotationKey              = "zalando.org/backend-weights"
+	ratelimitAnnotationKey                   = "zalando.org/ratelimit"
+	skipperfilterAnnotationKey               = "zalando.org/skipper-filter"
+	skipperpredicateAnnotationKey            = "zalando.org/skipper-predicate"
+	skipperRoutesAnnotationKey               = "zalando.org/skipper-routes"
+	skipperLoadBalancerAnnotationKey         = "zalando.org/skipper-loadbalancer"
+	skipperBackendProtocolAnnotationKey      = "zalando.org/skipper-backend-protocol"
+	skipperBackendConcurrencyAnnotationKey   = "zalando.org/skipper-backend-concurrency"
+	skipperClientConcurrencyAnnotationKey    = "zalando.org/skipper-client-concurrency"
+	skipperCacheControlAnnotationKey         = "zalando.org/skipper-cache-control"
+	skipperEnsureRequestIDAnnotationKey      = "zalando.org/skipper-ensure-request-id"
+	skipperDecompressRequestAnnotationKey    = "zalando.org/skipper-decompress-request"
+	skipperPriorityAnnotationKey             = "zalando.org/skipper-priority"
+	skipperMaxRequestBodyRejectAnnotationKey = "zalando.org/skipper-max-request-body-reject"
+	skipperMaxResponseBodyAnnotationKey      = "zalando.org/skipper-max-response-body"
+	skipperCookieSameSiteAnnotationKey       = "zalando.org/skipper-cookie-samesite"
+	skipperBackendSNIAnnotationKey           = "zalando.org/skipper-backend-sni"
+	skipperFlushIntervalAnnotationKey        = "zalando.org/skipper-flush-interval"
+	skipperConnectTimeoutAnnotationKey       = "zalando.org/skipper-connect-timeout"
+	skipperRetryBudgetAnnotationKey          = "zalando.org/skipper-retry-budget"
+	pathModeAnnotationKey                    = "zalando.org/skipper-ingress-path-mode"
+	ingressOriginName                        = "ingress"
+	tlsSecretType                            = "kubernetes.io/tls"
+	tlsSecretDataCrt                         = "tls.crt"
+	tlsSecretDataKey                         = "tls.key"
 )
 
 type ingressContext struct {
@@ -38,53 +56,259 @@ type ingressContext struct {
 	ingress             *definitions.IngressItem
 	ingressV1           *definitions.IngressV1Item
 	logger              *log.Entry
+	diagnostics         *diagnostics
 	annotationFilters   []*eskip.Filter
 	annotationPredicate string
 	extraRoutes         []*eskip.Route
 	backendWeights      map[string]float64
+	cookieRoute         *cookieRoute
+	ipSplit             *ipSplit
+	shadowEvery         *shadowEvery
+	schedule            *schedule
+	allowedHosts        *allowedHosts
+	stickySession       *stickySession
+	securityHeaders     []*eskip.Filter
+	faultInjection      *faultInjection
+	forceStatus         *forceStatus
+	fallbackService     *fallbackService
+	endpointSelector    endpointSelector
+	breakerBypass       *eskip.Predicate
+	idempotentRetries   bool
+	backendHTTP1        *eskip.Filter
+	clientCert          *eskip.Predicate
+	allowedSource       *eskip.Predicate
 	pathMode            PathMode
+	ruleWeight          int
+	splitPredicates     int
+	priorityWeight      int
 	redirect            *redirectInfo
 	hostRoutes          map[string][]*eskip.Route
+	routeOwners         map[string]definitions.ResourceID
 	defaultFilters      defaultFilters
 	certificateRegistry *certregistry.CertRegistry
+	hostCerts           hostCerts
 }
 
 type ingress struct {
 	eastWestRangeDomains     []string
 	eastWestRangePredicates  []*eskip.Predicate
 	allowedExternalNames     []*regexp.Regexp
+	allowLocalExternalNames  bool
 	kubernetesEastWestDomain string
+	eastWestHosts            eastWestHosts
+	hostPortRx               string
 	pathMode                 PathMode
 	httpsRedirectCode        int
 	kubernetesEnableEastWest bool
 	ingressV1                bool
 	provideHTTPSRedirect     bool
+	strictAnnotationParsing  bool
+	rejectDuplicatePaths     bool
+	reverseSourcePredicate   bool
+	shardIndex               int
+	shardCount               int
+	backendWeightPrecision   int
+	autoHandleOptions        bool
+	autoHandleOptionsHeaders map[string]string
+	defaultLBAlgorithm       string
+	normalizePaths           bool
+	defaultSecurityHeaders   bool
+	inspectionFilter         []*eskip.Filter
+
+	defaultBackendConflictPolicy DefaultBackendConflictPolicy
+	tlsConflictPolicy            TLSConflictPolicy
+
+	// route ID -> ingress, of the last conversion
+	routeOwners map[string]definitions.ResourceID
+
+	// route ID -> endpoints of the sticky session routes, of the last conversion
+	stickyEndpoints map[string]stickyEndpoints
+
+	// number of the ingresses processed by the last conversion
+	processed int
+}
+
+// route weights of the priority classes, set with the zalando.org/skipper-priority
+// annotation. They are large enough not to be overridden by the weights of the
+// overlapping rules.
+var priorityWeights = map[string]int{
+	"low":    100,
+	"medium": 200,
+	"high":   300,
 }
 
 var nonWord = regexp.MustCompile(`\W`)
 
 var errNotAllowedExternalName = errors.New("ingress with not allowed external name service")
 
+// diagnostics logs the problems of an ingress found during the conversion.
+// Each problem is logged only once, even when it affects multiple rules or
+// paths of the ingress.
+type diagnostics struct {
+	logger *log.Entry
+	logged map[string]bool
+}
+
+func newDiagnostics(logger *log.Entry) *diagnostics {
+	return &diagnostics{logger: logger, logged: make(map[string]bool)}
+}
+
+func (d *diagnostics) logOnce(level log.Level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if d.logged[msg] {
+		return
+	}
+
+	d.logged[msg] = true
+	d.logger.Log(level, msg)
+}
+
+func (d *diagnostics) Errorf(format string, args ...interface{}) {
+	d.logOnce(log.ErrorLevel, format, args...)
+}
+
+func (d *diagnostics) Warnf(format string, args ...interface{}) {
+	d.logOnce(log.WarnLevel, format, args...)
+}
+
+// checkBackendPort logs a diagnostic, when the port of a backend service is
+// not specified. Unless the service has a single unnamed port, the routes of
+// these backends are shunted, because no endpoints can be found for them.
+func (ic *ingressContext) checkBackendPort(service string, port fmt.Stringer) {
+	if p := port.String(); p == "" || p == "0" {
+		ic.diagnostics.Errorf("Backend port not specified for service %s", service)
+	}
+}
+
 func (ic *ingressContext) addHostRoute(host string, route *eskip.Route) {
+	if route != nil && isEastWestRouteID(route.Id) && !ic.dedupEastWestRoute(route) {
+		return
+	}
+
 	ic.hostRoutes[host] = append(ic.hostRoutes[host], route)
+	if route != nil {
+		ic.routeOwners[route.Id] = ic.resourceID()
+	}
+}
+
+func (ic *ingressContext) resourceID() definitions.ResourceID {
+	if ic.ingressV1 != nil {
+		return ic.ingressV1.Metadata.ToResourceID()
+	}
+
+	return ic.ingress.Metadata.ToResourceID()
+}
+
+// applyAnnotations applies the filters and predicates from the ingress annotations,
+// and the pre-configured default filters, to a route created for the ingress.
+func (ic *ingressContext) applyAnnotations(r *eskip.Route, namespace, svcName string) {
+	// safe prepend, see: https://play.golang.org/p/zg5aGKJpRyK
+	filters := make([]*eskip.Filter, len(r.Filters)+len(ic.annotationFilters))
+	copy(filters, ic.annotationFilters)
+	copy(filters[len(ic.annotationFilters):], r.Filters)
+	r.Filters = filters
+
+	// add pre-configured default filters
+	df, err := ic.defaultFilters.getNamed(namespace, svcName)
+	if err != nil {
+		ic.logger.Errorf("Failed to retrieve default filters: %v.", err)
+	} else {
+		// it's safe to prepend, because type defaultFilters copies the slice during get()
+		r.Filters = append(df, r.Filters...)
+	}
+
+	if len(ic.securityHeaders) > 0 {
+		filters := make([]*eskip.Filter, len(r.Filters)+len(ic.securityHeaders))
+		copy(filters, ic.securityHeaders)
+		copy(filters[len(ic.securityHeaders):], r.Filters)
+		r.Filters = filters
+	}
+
+	if ic.backendHTTP1 != nil && r.BackendType != eskip.ShuntBackend {
+		r.Filters = append(r.Filters, ic.backendHTTP1)
+	}
+
+	err = applyAnnotationPredicates(ic.pathMode, r, ic.annotationPredicate)
+	if err != nil {
+		ic.logger.Errorf("failed to apply annotation predicates: %v", err)
+	}
+
+	if ic.clientCert != nil {
+		r.Predicates = append(r.Predicates, eskip.CopyPredicate(ic.clientCert))
+	}
+
+	if ic.allowedSource != nil {
+		r.Predicates = append(r.Predicates, eskip.CopyPredicate(ic.allowedSource))
+	}
+
+	ic.allowedHosts.restrict(r)
+	setRuleWeight(r, ic.priorityWeight+ic.ruleWeight)
+	setPathDepthWeight(r)
 }
 
 func newIngress(o Options) *ingress {
+	// the options are validated when creating the client
+	portRx, _ := hostPortRx(o.HostMatchPort, o.HostTrailingDot)
+	inspectionFilter, _ := eskip.ParseFilters(o.InspectionFilter)
+
 	return &ingress{
+		hostPortRx:               portRx,
 		ingressV1:                o.KubernetesIngressV1,
 		provideHTTPSRedirect:     o.ProvideHTTPSRedirect,
 		httpsRedirectCode:        o.HTTPSRedirectCode,
 		pathMode:                 o.PathMode,
 		kubernetesEnableEastWest: o.KubernetesEnableEastWest,
 		kubernetesEastWestDomain: o.KubernetesEastWestDomain,
+		eastWestHosts:            newEastWestHosts(o),
 		eastWestRangeDomains:     o.KubernetesEastWestRangeDomains,
 		eastWestRangePredicates:  o.KubernetesEastWestRangePredicates,
 		allowedExternalNames:     o.AllowedExternalNames,
+		allowLocalExternalNames:  o.AllowLocalExternalNames,
+		strictAnnotationParsing:  o.StrictAnnotationParsing,
+		rejectDuplicatePaths:     o.RejectDuplicatePaths,
+		reverseSourcePredicate:   o.ReverseSourcePredicate,
+		shardIndex:               o.ShardIndex,
+		shardCount:               o.ShardCount,
+		backendWeightPrecision:   o.BackendWeightPrecision,
+		autoHandleOptions:        o.AutoHandleOptions,
+		autoHandleOptionsHeaders: o.AutoHandleOptionsHeaders,
+		defaultLBAlgorithm:       o.DefaultLoadBalancerAlgorithm,
+		normalizePaths:           o.NormalizePaths,
+		defaultSecurityHeaders:   o.DefaultSecurityHeaders,
+		inspectionFilter:         inspectionFilter,
+
+		defaultBackendConflictPolicy: o.DefaultBackendConflictPolicy,
+		tlsConflictPolicy:            o.TLSConflictPolicy,
 	}
 }
 
-func getLoadBalancerAlgorithm(m *definitions.Metadata) string {
-	algorithm := defaultLoadBalancerAlgorithm
+// ownsIngress tells whether the ingress belongs to the shard of this instance.
+// The shard of an ingress is the hash of its namespace and name, modulo the
+// shard count.
+func (ing *ingress) ownsIngress(m *definitions.Metadata) bool {
+	if ing.shardCount <= 1 || m == nil {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(m.Namespace + "/" + m.Name))
+	return int(h.Sum32()%uint32(ing.shardCount)) == ing.shardIndex
+}
+
+// breakerBypassSource parses the breaker bypass annotation, a comma separated
+// list of the CIDRs of the operators, and returns the source predicate of the
+// breaker bypass routes, or nil, when the ingress doesn't enable them.
+func (ing *ingress) breakerBypassSource(m *definitions.Metadata, logger *log.Entry) *eskip.Predicate {
+	val, ok := m.Annotations[skipperBreakerBypassAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	return ing.sourceCIDRsPredicate(skipperBreakerBypassAnnotationKey, val, logger)
+}
+
+func getLoadBalancerAlgorithm(m *definitions.Metadata, defaultAlgorithm string) string {
+	algorithm := defaultAlgorithm
 	if algorithmAnnotationValue, ok := m.Annotations[skipperLoadBalancerAnnotationKey]; ok {
 		algorithm = algorithmAnnotationValue
 	}
@@ -115,11 +339,16 @@ func externalNameRoute(
 	svc *service,
 	servicePort *servicePort,
 	allowedNames []*regexp.Regexp,
+	allowLocal bool,
 ) (*eskip.Route, error) {
 	if !isExternalDomainAllowed(allowedNames, svc.Spec.ExternalName) {
 		return nil, fmt.Errorf("%w: %s", errNotAllowedExternalName, svc.Spec.ExternalName)
 	}
 
+	if !allowLocal && isLocalExternalName(svc.Spec.ExternalName) {
+		return nil, fmt.Errorf("%w: local address %s", errNotAllowedExternalName, svc.Spec.ExternalName)
+	}
+
 	scheme := "https"
 	if n, _ := servicePort.TargetPort.Number(); n != 443 {
 		scheme = "http"
@@ -140,6 +369,80 @@ func externalNameRoute(
 	}, nil
 }
 
+// roundTraffic rounds a traffic weight to the given number of decimal places. Non-zero weights
+// are not rounded to zero, because backends with zero weight get no routes.
+func roundTraffic(weight float64, precision int) float64 {
+	p := math.Pow10(precision)
+	rounded := math.Round(weight*p) / p
+	if rounded == 0 && weight > 0 {
+		return 1 / p
+	}
+
+	return rounded
+}
+
+// companionRoute creates a route next to a route of the ingress, handling a
+// subset of its requests differently. It copies the route with the ID
+// suffix, prepends the predicates selecting the subset, and, when set, adds
+// the weight to the weight of the route.
+func companionRoute(r *eskip.Route, suffix string, weight int, p ...*eskip.Predicate) *eskip.Route {
+	cr := eskip.Copy(r)
+	cr.Id = fmt.Sprintf("%s_%s", r.Id, suffix)
+	cr.Predicates = append(p, cr.Predicates...)
+	if weight > 0 {
+		addRouteWeight(cr, weight)
+	}
+
+	return cr
+}
+
+// addRouteWeight increases the weight of the route. Only the last Weight
+// predicate of a route is taken into account by the routing, so when the route
+// already has one, e.g. from the priority or the rule weights, its value is
+// increased instead of adding another one.
+func addRouteWeight(r *eskip.Route, weight int) {
+	for i := len(r.Predicates) - 1; i >= 0; i-- {
+		p := r.Predicates[i]
+		if p.Name != predicates.WeightName || len(p.Args) != 1 {
+			continue
+		}
+
+		if w, ok := p.Args[0].(float64); ok {
+			p.Args = []interface{}{w + float64(weight)}
+			return
+		}
+	}
+
+	r.Predicates = append([]*eskip.Predicate{{
+		Name: predicates.WeightName,
+		Args: []interface{}{float64(weight)},
+	}}, r.Predicates...)
+}
+
+// shuntCompanionRoute makes a companion route respond with the filters,
+// without proxying the requests to the backend.
+func shuntCompanionRoute(r *eskip.Route, f ...*eskip.Filter) {
+	r.Filters = f
+	r.BackendType = eskip.ShuntBackend
+	r.Backend = ""
+	r.LBEndpoints = nil
+	r.LBAlgorithm = ""
+}
+
+// truePredicates returns n True predicates, used to make a route precede the
+// routes splitting the traffic of a path with up to n predicates.
+func truePredicates(n int) []*eskip.Predicate {
+	p := make([]*eskip.Predicate, n)
+	for i := range p {
+		p[i] = &eskip.Predicate{
+			Name: predicates.TrueName,
+			Args: []interface{}{},
+		}
+	}
+
+	return p
+}
+
 func setTraffic(r *eskip.Route, svcName string, weight float64, noopCount int) {
 	// add traffic predicate if traffic weight is between 0.0 and 1.0
 	if 0.0 < weight && weight < 1.0 {
@@ -157,6 +460,16 @@ func setTraffic(r *eskip.Route, svcName string, weight float64, noopCount int) {
 	}
 }
 
+// splitPredicateCount returns the number of predicates added by setTraffic.
+func splitPredicateCount(weight float64, noopCount int) int {
+	n := noopCount
+	if 0.0 < weight && weight < 1.0 {
+		n++
+	}
+
+	return n
+}
+
 func applyAnnotationPredicates(m PathMode, r *eskip.Route, annotation string) error {
 	if annotation == "" {
 		return nil
@@ -193,8 +506,52 @@ func applyAnnotationPredicates(m PathMode, r *eskip.Route, annotation string) er
 	return nil
 }
 
-func addExtraRoutes(ic ingressContext, ruleHost, path, pathType, eastWestDomain string, enableEastWest bool) {
-	hosts := []string{createHostRx(ruleHost)}
+// ruleWeights returns the weights for the routes of the ingress rules. Rules
+// with the same host can match the same request, and for these the weight
+// decreases with the rule index, so that the earlier rules take precedence.
+func ruleWeights(hosts []string) []int {
+	count := make(map[string]int)
+	for _, h := range hosts {
+		count[h]++
+	}
+
+	weights := make([]int, len(hosts))
+	for i, h := range hosts {
+		count[h]--
+		weights[i] = count[h]
+	}
+
+	return weights
+}
+
+func setRuleWeight(r *eskip.Route, weight int) {
+	if weight <= 0 {
+		return
+	}
+
+	r.Predicates = append(r.Predicates, &eskip.Predicate{
+		Name: predicates.WeightName,
+		Args: []interface{}{float64(weight)},
+	})
+}
+
+// setPathDepthWeight increases the weight of the routes with a path regexp by
+// the depth of the path, so that from the overlapping paths, e.g. ^/a and
+// ^/a/b, the more specific one handles the requests to the deeper paths. The
+// routes with a PathSubtree predicate don't need it, because the routing tree
+// already selects the longest matching subtree.
+func setPathDepthWeight(r *eskip.Route) {
+	if len(r.PathRegexps) == 0 {
+		return
+	}
+
+	if depth := strings.Count(r.PathRegexps[0], "/") - 1; depth > 0 {
+		addRouteWeight(r, depth)
+	}
+}
+
+func addExtraRoutes(ic ingressContext, ruleHost, path, pathType, eastWestDomain, portRx string, eastWestHosts eastWestHosts, enableEastWest bool) {
+	hosts := []string{createHostRxPort(portRx, ruleHost)}
 	var ns, name string
 	if ic.ingressV1 != nil {
 		name = ic.ingressV1.Metadata.Name
@@ -216,6 +573,7 @@ func addExtraRoutes(ic ingressContext, ruleHost, path, pathType, eastWestDomain
 			ruleHost+strings.Replace(path, "/", "_", -1),
 			extraIndex)
 		setPathV1(ic.pathMode, &route, pathType, path)
+		setPathDepthWeight(&route)
 		if n := countPathRoutes(&route); n <= 1 {
 			ic.addHostRoute(ruleHost, &route)
 			ic.redirect.updateHost(ruleHost)
@@ -223,8 +581,8 @@ func addExtraRoutes(ic ingressContext, ruleHost, path, pathType, eastWestDomain
 			log.Errorf("Failed to add route having %d path routes: %v", n, r)
 		}
 		if enableEastWest {
-			ewRoute := createEastWestRouteIng(eastWestDomain, name, ns, &route)
-			ewHost := fmt.Sprintf("%s.%s.%s", name, ns, eastWestDomain)
+			ewRoute := createEastWestRouteIng(eastWestHosts, eastWestDomain, name, ns, &route)
+			ewHost := eastWestHosts.host(name, ns, eastWestDomain)
 			ic.addHostRoute(ewHost, ewRoute)
 		}
 	}
@@ -244,7 +602,11 @@ func countPathRoutes(r *eskip.Route) int {
 }
 
 // parse filter and ratelimit annotation
-func annotationFilter(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+// parse filter annotations. When the filters from the zalando.org/skipper-auth,
+// zalando.org/ratelimit or zalando.org/skipper-filter annotations cannot be
+// parsed, they are dropped, and the parse error is returned together with the
+// rest of the filters.
+func annotationFilter(m *definitions.Metadata, logger *log.Entry) ([]*eskip.Filter, error) {
 	var annotationFilter string
 	if ratelimitAnnotationValue, ok := m.Annotations[ratelimitAnnotationKey]; ok {
 		annotationFilter = ratelimitAnnotationValue
@@ -256,14 +618,402 @@ func annotationFilter(m *definitions.Metadata, logger *log.Entry) []*eskip.Filte
 		annotationFilter += val
 	}
 
+	// authentication filters come first, to reject the unauthenticated
+	// requests before any other filter is executed
+	annotationFilters, parseErr := authFilters(m)
 	if annotationFilter != "" {
-		annotationFilters, err := eskip.ParseFilters(annotationFilter)
-		if err == nil {
-			return annotationFilters
+		parsed, err := eskip.ParseFilters(annotationFilter)
+		if err != nil && parseErr == nil {
+			parseErr = err
+		}
+
+		annotationFilters = append(annotationFilters, parsed...)
+	}
+
+	setBreakerHalfOpen(annotationFilters, m, logger)
+
+	// the request body is decompressed before any other filter processes it,
+	// e.g. to limit the decompressed size
+	if f := decompressRequestFilter(m, logger); f != nil {
+		annotationFilters = append([]*eskip.Filter{f}, annotationFilters...)
+	}
+
+	// the request ID is set first, to be available for all the other filters
+	if f := ensureRequestIDFilter(m, logger); f != nil {
+		annotationFilters = append([]*eskip.Filter{f}, annotationFilters...)
+	}
+
+	if f := backendConcurrencyFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := clientConcurrencyFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := cacheControlFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := maxRequestBodyFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := maxResponseBodyFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := cookieSameSiteFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := responseRewriteFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := ratelimitKeyFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := backendSNIFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := flushIntervalFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := connectTimeoutFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	if f := retryBudgetFilter(m, logger); f != nil {
+		annotationFilters = append(annotationFilters, f)
+	}
+
+	annotationFilters = append(annotationFilters, stageFilters(m, logger)...)
+	annotationFilters = append(annotationFilters, otelAttributesFilters(m, logger)...)
+
+	return annotationFilters, parseErr
+}
+
+// ingressAnnotationFilters returns the filters from the annotations of an
+// ingress. The returned flag is false when the ingress needs to be skipped,
+// because the filters cannot be parsed and strict annotation parsing is
+// enabled.
+func (ing *ingress) ingressAnnotationFilters(m *definitions.Metadata, logger *log.Entry) ([]*eskip.Filter, bool) {
+	f, err := annotationFilter(m, logger)
+	if err != nil {
+		if ing.strictAnnotationParsing {
+			logger.Errorf("Skipping ingress, can not parse annotation filters: %v", err)
+			return nil, false
 		}
+
 		logger.Errorf("Can not parse annotation filters: %v", err)
 	}
-	return nil
+
+	return append(f, ing.inspectionFilters(m, logger)...), true
+}
+
+// parse ensure request ID annotation, and create a requestId filter setting
+// the X-Request-Id header when it is missing
+func ensureRequestIDFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperEnsureRequestIDAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, boolean expected: %s", skipperEnsureRequestIDAnnotationKey, val)
+		return nil
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	return &eskip.Filter{Name: filters.RequestIdName}
+}
+
+// parse decompress request annotation, and create a decompressRequest filter
+// decompressing the request body before proxying
+func decompressRequestFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperDecompressRequestAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, boolean expected: %s", skipperDecompressRequestAnnotationKey, val)
+		return nil
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	return &eskip.Filter{Name: filters.DecompressRequestName}
+}
+
+// parse priority annotation, and return the route weight of the priority class
+func priorityWeight(m *definitions.Metadata, logger *log.Entry) int {
+	val, ok := m.Annotations[skipperPriorityAnnotationKey]
+	if !ok {
+		return 0
+	}
+
+	w, ok := priorityWeights[val]
+	if !ok {
+		logger.Errorf("Invalid %s annotation, expected one of high, medium or low: %s", skipperPriorityAnnotationKey, val)
+		return 0
+	}
+
+	return w
+}
+
+// parse backend concurrency annotation, and create a lifo filter limiting
+// the number of concurrent requests to the backend
+func backendConcurrencyFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperBackendConcurrencyAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	limit, err := strconv.Atoi(val)
+	if err != nil || limit <= 0 {
+		logger.Errorf("Invalid %s annotation, positive integer expected: %s", skipperBackendConcurrencyAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.LifoName,
+		Args: []interface{}{float64(limit)},
+	}
+}
+
+// parse client concurrency annotation, and create a clientConcurrency filter
+// limiting the concurrent requests of a single client
+func clientConcurrencyFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperClientConcurrencyAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	limit, err := strconv.Atoi(val)
+	if err != nil || limit <= 0 {
+		logger.Errorf("Invalid %s annotation, positive integer expected: %s", skipperClientConcurrencyAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.ClientConcurrencyName,
+		Args: []interface{}{float64(limit)},
+	}
+}
+
+// parse cache control annotation, and create a filter setting the
+// Cache-Control response header
+func cacheControlFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperCacheControlAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" {
+		logger.Errorf("Invalid %s annotation, empty value", skipperCacheControlAnnotationKey)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.SetResponseHeaderName,
+		Args: []interface{}{"Cache-Control", val},
+	}
+}
+
+// byte size units of the body size annotations
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// parseByteSize parses a positive size with an optional unit, e.g. 512, 64KB or 5Mi
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	unit := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if n <= 0 || n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("size out of range: %d", n)
+	}
+
+	return n * unit, nil
+}
+
+// parse max request body reject annotation, and create a maxRequestBody
+// filter responding with 413 to the requests with a larger body
+func maxRequestBodyFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperMaxRequestBodyRejectAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	size, err := parseByteSize(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, positive size expected, e.g. 5MB: %s", skipperMaxRequestBodyRejectAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.MaxRequestBodyName,
+		Args: []interface{}{float64(size)},
+	}
+}
+
+// parse max response body annotation, and create a maxResponseBody filter
+// limiting the size of the response body
+func maxResponseBodyFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperMaxResponseBodyAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	size, err := parseByteSize(val)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, positive size expected, e.g. 50MB: %s", skipperMaxResponseBodyAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.MaxResponseBodyName,
+		Args: []interface{}{float64(size)},
+	}
+}
+
+// parse cookie SameSite annotation, and create a cookieSameSite filter
+// setting the SameSite attribute of the response cookies
+func cookieSameSiteFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperCookieSameSiteAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	value, ok := cookie.ParseSameSite(val)
+	if !ok {
+		logger.Errorf("Invalid %s annotation, one of %s expected: %s", skipperCookieSameSiteAnnotationKey, strings.Join(cookie.SameSiteValues, ", "), val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.CookieSameSiteName,
+		Args: []interface{}{value},
+	}
+}
+
+// parse backend SNI annotation, and create a backendSNI filter setting the
+// server name presented in the TLS handshake with the https backends
+func backendSNIFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperBackendSNIAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" {
+		logger.Errorf("Invalid %s annotation, empty value", skipperBackendSNIAnnotationKey)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.BackendSNIName,
+		Args: []interface{}{val},
+	}
+}
+
+// parse flush interval annotation, and create a flushInterval filter setting
+// how often the streamed responses are flushed to the client
+func flushIntervalFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperFlushIntervalAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	if d, err := time.ParseDuration(val); err != nil || d <= 0 {
+		logger.Errorf("Invalid %s annotation, positive duration expected, e.g. 100ms: %s", skipperFlushIntervalAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.FlushIntervalName,
+		Args: []interface{}{val},
+	}
+}
+
+// parse connect timeout annotation, and create a connectTimeout filter
+// setting the timeout of connecting to the backend
+func connectTimeoutFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperConnectTimeoutAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	if d, err := time.ParseDuration(val); err != nil || d <= 0 {
+		logger.Errorf("Invalid %s annotation, positive duration expected, e.g. 2s: %s", skipperConnectTimeoutAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.ConnectTimeoutName,
+		Args: []interface{}{val},
+	}
+}
+
+// parse retry budget annotation, and create a retryBudget filter limiting
+// the retries of the failed backend requests
+func retryBudgetFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperRetryBudgetAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var budget struct {
+		Ratio *float64 `json:"ratio"`
+		Min   int      `json:"min"`
+	}
+
+	if err := json.Unmarshal([]byte(val), &budget); err != nil || budget.Ratio == nil || *budget.Ratio < 0 || *budget.Ratio > 1 || budget.Min < 0 {
+		logger.Errorf(`Invalid %s annotation, ratio between 0 and 1 and non-negative min expected, e.g. {"ratio": 0.1, "min": 3}: %s`, skipperRetryBudgetAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.RetryBudgetName,
+		Args: []interface{}{*budget.Ratio, float64(budget.Min)},
+	}
 }
 
 // parse predicate annotation
@@ -324,7 +1074,7 @@ func (ing *ingress) addCatchAllRoutes(host string, r *eskip.Route, redirect *red
 	}
 	routes := []*eskip.Route{catchAll}
 	if ing.kubernetesEnableEastWest {
-		if ew := createEastWestRouteIng(ing.kubernetesEastWestDomain, r.Name, r.Namespace, catchAll); ew != nil {
+		if ew := createEastWestRouteIng(ing.eastWestHosts, ing.kubernetesEastWestDomain, r.Name, r.Namespace, catchAll); ew != nil {
 			routes = append(routes, ew)
 		}
 	}
@@ -358,8 +1108,8 @@ func hasCatchAllRoutes(routes []*eskip.Route) bool {
 	return false
 }
 
-// addHostTLSCert adds a TLS certificate to the certificate registry per host when the referenced
-// secret is found and is a valid TLS secret.
+// addHostTLSCert collects a TLS certificate per host, to be added to the certificate registry,
+// when the referenced secret is found and is a valid TLS secret.
 func addHostTLSCert(ic ingressContext, hosts []string, secretID *definitions.ResourceID) {
 	secret, ok := ic.state.secrets[*secretID]
 	if !ok {
@@ -372,10 +1122,7 @@ func addHostTLSCert(ic ingressContext, hosts []string, secretID *definitions.Res
 		return
 	}
 	for _, host := range hosts {
-		err := ic.certificateRegistry.ConfigureCertificate(host, cert)
-		if err != nil {
-			log.Error(err)
-		}
+		ic.hostCerts.add(host, hostCert{owner: ic.resourceID(), secret: *secretID, cert: cert})
 	}
 }
 
@@ -390,32 +1137,58 @@ func (ing *ingress) convert(state *clusterState, df defaultFilters, r *certregis
 	}
 	routes := make([]*eskip.Route, 0, len(state.ingresses))
 	hostRoutes := make(map[string][]*eskip.Route)
+	routeOwners := make(map[string]definitions.ResourceID)
 	redirect := createRedirectInfo(ing.provideHTTPSRedirect, ing.httpsRedirectCode)
+	var (
+		processed       int
+		defaultBackends []defaultBackendRoute
+	)
+	hc := make(hostCerts)
 	if ing.ingressV1 {
 		for _, i := range state.ingressesV1 {
-			r, err := ing.ingressV1Route(i, redirect, state, hostRoutes, df, r)
+			if !ing.ownsIngress(i.Metadata) {
+				continue
+			}
+
+			processed++
+
+			d, err := ing.ingressV1Route(i, redirect, state, hostRoutes, routeOwners, df, r, hc)
 			if err != nil {
 				return nil, err
 			}
-			if r != nil {
-				routes = append(routes, r)
-				if ing.kubernetesEnableEastWest {
-					ewIngInfo[r.Id] = []string{i.Metadata.Namespace, i.Metadata.Name}
-				}
+			if d != nil {
+				defaultBackends = append(defaultBackends, *d)
 			}
 		}
 
 	} else {
 		for _, i := range state.ingresses {
-			r, err := ing.ingressRoute(i, redirect, state, hostRoutes, df)
+			if !ing.ownsIngress(i.Metadata) {
+				continue
+			}
+
+			processed++
+
+			d, err := ing.ingressRoute(i, redirect, state, hostRoutes, routeOwners, df)
 			if err != nil {
 				return nil, err
 			}
-			if r != nil {
-				routes = append(routes, r)
-				if ing.kubernetesEnableEastWest {
-					ewIngInfo[r.Id] = []string{i.Metadata.Namespace, i.Metadata.Name}
-				}
+			if d != nil {
+				defaultBackends = append(defaultBackends, *d)
+			}
+		}
+	}
+
+	if r != nil {
+		ing.configureHostCerts(hc, r)
+	}
+
+	for _, d := range ing.resolveDefaultBackends(defaultBackends, hostRoutes, routeOwners) {
+		for _, dr := range d.routes() {
+			routes = append(routes, dr)
+			routeOwners[dr.Id] = d.owner
+			if ing.kubernetesEnableEastWest {
+				ewIngInfo[dr.Id] = []string{d.owner.Namespace, d.owner.Name}
 			}
 		}
 	}
@@ -428,6 +1201,10 @@ func (ing *ingress) convert(state *clusterState, df defaultFilters, r *certregis
 		applyEastWestRange(ing.eastWestRangeDomains, ing.eastWestRangePredicates, host, rs)
 		routes = append(routes, rs...)
 
+		if ing.autoHandleOptions {
+			routes = append(routes, ing.createOptionsRoutes(rs)...)
+		}
+
 		// if routes were configured, but there is no catchall route
 		// defined for the host name, create a route which returns 404
 		if !hasCatchAllRoutes(rs) {
@@ -439,7 +1216,11 @@ func (ing *ingress) convert(state *clusterState, df defaultFilters, r *certregis
 		ewroutes := make([]*eskip.Route, 0, len(routes))
 		for _, r := range routes {
 			if v, ok := ewIngInfo[r.Id]; ok {
-				ewroutes = append(ewroutes, createEastWestRouteIng(ing.kubernetesEastWestDomain, v[0], v[1], r))
+				ewr := createEastWestRouteIng(ing.eastWestHosts, ing.kubernetesEastWestDomain, v[0], v[1], r)
+				ewroutes = append(ewroutes, ewr)
+				if ewr != nil {
+					routeOwners[ewr.Id] = newResourceID(v[0], v[1])
+				}
 			}
 		}
 		l := len(routes)
@@ -447,6 +1228,9 @@ func (ing *ingress) convert(state *clusterState, df defaultFilters, r *certregis
 		log.Infof("enabled east west routes: %d %d %d %d", l, len(routes), len(ewroutes), len(hostRoutes))
 	}
 
+	ing.stickyEndpoints = applyStickyFallbacks(routes, ing.stickyEndpoints)
+	ing.routeOwners = routeOwners
+	ing.processed = processed
 	return routes, nil
 }
 
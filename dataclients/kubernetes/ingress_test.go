@@ -1,9 +1,24 @@
 package kubernetes_test
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/zalando/skipper/dataclients/kubernetes"
 	"github.com/zalando/skipper/dataclients/kubernetes/kubernetestest"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/predicates/cookie"
+	"github.com/zalando/skipper/predicates/source"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
 )
 
 func TestIngressFixtures(t *testing.T) {
@@ -30,3 +45,224 @@ func TestIngressV1Fixtures(t *testing.T) {
 		"testdata/ingressV1/tls",
 	)
 }
+
+// testIngressV1Routing creates a routing table from the routes of the
+// ingresses in the spec.
+func testIngressV1Routing(t *testing.T, spec io.Reader) (*routing.Routing, func()) {
+	a, err := kubernetestest.NewAPI(kubernetestest.TestAPIOptions{}, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(a)
+	dc, err := kubernetes.New(kubernetes.Options{KubernetesURL: s.URL, KubernetesIngressV1: true})
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+
+	r, err := dc.LoadAll()
+	dc.Close()
+	s.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := routing.New(routing.Options{
+		DataClients:     []routing.DataClient{testdataclient.New(r)},
+		FilterRegistry:  builtin.MakeRegistry(),
+		Predicates:      []routing.PredicateSpec{cookie.New(), source.New()},
+		SignalFirstLoad: true,
+	})
+	<-rt.FirstLoad()
+	return rt, rt.Close
+}
+
+func TestIngressV1ExactPathWithDefaultBackend(t *testing.T) {
+	const spec = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  namespace: foo
+  name: qux
+spec:
+  defaultBackend:
+    service:
+      name: default
+      port:
+        number: 8080
+  rules:
+  - host: www.example.org
+    http:
+      paths:
+      - path: /foo
+        pathType: Exact
+        backend:
+          service:
+            name: bar
+            port:
+              number: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  namespace: foo
+  name: bar
+spec:
+  clusterIP: 10.3.190.97
+  ports:
+  - port: 8080
+    protocol: TCP
+    targetPort: 8080
+  type: ClusterIP
+---
+apiVersion: v1
+kind: Endpoints
+metadata:
+  namespace: foo
+  name: bar
+subsets:
+- addresses:
+  - ip: 10.2.9.103
+  ports:
+  - port: 8080
+    protocol: TCP
+---
+apiVersion: v1
+kind: Service
+metadata:
+  namespace: foo
+  name: default
+spec:
+  clusterIP: 10.3.190.98
+  ports:
+  - port: 8080
+    protocol: TCP
+    targetPort: 8080
+  type: ClusterIP
+---
+apiVersion: v1
+kind: Endpoints
+metadata:
+  namespace: foo
+  name: default
+subsets:
+- addresses:
+  - ip: 10.2.9.104
+  ports:
+  - port: 8080
+    protocol: TCP
+`
+
+	rt, closeRouting := testIngressV1Routing(t, bytes.NewBufferString(spec))
+	defer closeRouting()
+
+	for path, backend := range map[string]string{
+		"/foo":     "http://10.2.9.103:8080",
+		"/bar":     "http://10.2.9.104:8080",
+		"/foo/bar": "http://10.2.9.104:8080",
+		"/":        "http://10.2.9.104:8080",
+	} {
+		req := &http.Request{URL: &url.URL{Path: path}, Host: "www.example.org"}
+		route, _ := rt.Route(req)
+		if route == nil {
+			t.Errorf("no route found for %s", path)
+			continue
+		}
+
+		if route.Backend != backend {
+			t.Errorf("expected %s to be routed to %s, got: %s", path, backend, route.Backend)
+		}
+	}
+}
+
+func TestIngressV1ForceStatus(t *testing.T) {
+	for _, fixture := range []string{
+		"ing-with-force-status-annotation",
+		"ing-with-force-status-priority-sticky-session-annotation",
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			spec, err := os.Open("testdata/ingressV1/ingress-data/" + fixture + ".yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer spec.Close()
+
+			rt, closeRouting := testIngressV1Routing(t, spec)
+			defer closeRouting()
+
+			for _, path := range []string{"/", "/api", "/foo/bar"} {
+				for _, withCookie := range []bool{false, true} {
+					req := &http.Request{URL: &url.URL{Path: path}, Host: "www.example.org", Header: make(http.Header)}
+					if withCookie {
+						req.AddCookie(&http.Cookie{Name: "session", Value: "foo"})
+					}
+
+					route, _ := rt.Route(req)
+					if route == nil {
+						t.Errorf("no route found for %s", path)
+						continue
+					}
+
+					if !strings.HasSuffix(route.Id, "_force_status") || route.BackendType != eskip.ShuntBackend {
+						t.Errorf("expected the forced status route for %s, cookie: %v, got: %s", path, withCookie, route.Id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIngressV1FallbackService(t *testing.T) {
+	spec, err := os.Open("testdata/ingressV1/ingress-data/ing-with-fallback-service-annotation.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer spec.Close()
+
+	rt, closeRouting := testIngressV1Routing(t, spec)
+	defer closeRouting()
+
+	req := &http.Request{URL: &url.URL{Path: "/foo"}, Host: "www.example.org"}
+	route, _ := rt.Route(req)
+	if route == nil {
+		t.Fatal("no route found")
+	}
+
+	expected := []string{"http://10.2.9.110:9090", "http://10.2.9.111:9090"}
+	if route.BackendType != eskip.LBBackend || !reflect.DeepEqual(route.Route.LBEndpoints, expected) {
+		t.Errorf("expected the fallback service to be used, got: %s", route.Id)
+	}
+}
+
+func TestIngressV1BreakerBypass(t *testing.T) {
+	spec, err := os.Open("testdata/ingressV1/ingress-data/ing-with-breaker-bypass-annotation.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer spec.Close()
+
+	rt, closeRouting := testIngressV1Routing(t, spec)
+	defer closeRouting()
+
+	for remoteAddr, bypass := range map[string]bool{
+		"10.2.3.4:1234":     true,
+		"192.168.1.10:1234": true,
+		"192.168.1.11:1234": false,
+		"10.3.0.1:1234":     false,
+		"203.0.113.1:1234":  false,
+	} {
+		req := &http.Request{URL: &url.URL{Path: "/"}, Host: "www.example.org", RemoteAddr: remoteAddr}
+		route, _ := rt.Route(req)
+		if route == nil {
+			t.Errorf("no route found for %s", remoteAddr)
+			continue
+		}
+
+		if strings.HasSuffix(route.Id, "_breaker_bypass") != bypass {
+			t.Errorf("unexpected route for %s, expected bypass: %v, got: %s", remoteAddr, bypass, route.Id)
+		}
+	}
+}
@@ -4,26 +4,31 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 )
 
 type servicePort struct {
-	Name       string                   `json:"name"`
-	Port       int                      `json:"port"`
-	TargetPort *definitions.BackendPort `json:"targetPort"` // string or int
+	Name        string                   `json:"name"`
+	Port        int                      `json:"port"`
+	TargetPort  *definitions.BackendPort `json:"targetPort"` // string or int
+	AppProtocol string                   `json:"appProtocol"`
 }
 
+// port names are matched case-insensitive, to be lenient with manifests
+// using mixed case port names
+
 func (sp servicePort) matchingPort(svcPort definitions.BackendPort) bool {
 	s := svcPort.String()
 	spt := strconv.Itoa(sp.Port)
-	return s != "" && (spt == s || sp.Name == s)
+	return s != "" && (spt == s || strings.EqualFold(sp.Name, s))
 }
 
 func (sp servicePort) matchingPortV1(svcPort definitions.BackendPortV1) bool {
 	s := svcPort.String()
 	spt := strconv.Itoa(sp.Port)
-	return s != "" && (spt == s || sp.Name == s)
+	return s != "" && (spt == s || strings.EqualFold(sp.Name, s))
 }
 
 func (sp servicePort) String() string {
@@ -47,6 +52,10 @@ type serviceList struct {
 }
 
 func (s service) getServicePort(port definitions.BackendPort) (*servicePort, error) {
+	if len(s.Spec.Ports) == 0 {
+		return nil, errServiceWithoutPorts
+	}
+
 	for _, sp := range s.Spec.Ports {
 		if sp.matchingPort(port) && sp.TargetPort != nil {
 			return sp, nil
@@ -56,6 +65,10 @@ func (s service) getServicePort(port definitions.BackendPort) (*servicePort, err
 }
 
 func (s service) getServicePortV1(port definitions.BackendPortV1) (*servicePort, error) {
+	if len(s.Spec.Ports) == 0 {
+		return nil, errServiceWithoutPorts
+	}
+
 	for _, sp := range s.Spec.Ports {
 		if sp.matchingPortV1(port) && sp.TargetPort != nil {
 			return sp, nil
@@ -87,65 +100,116 @@ func formatEndpoint(a *address, p *port, protocol string) string {
 	return protocol + "://" + net.JoinHostPort(a.IP, strconv.Itoa(p.Port))
 }
 
-func formatEndpointsForSubsetAddresses(addresses []*address, port *port, protocol string) []string {
+// endpointWeight returns how many times the address needs to be repeated in
+// the route backend, based on the weight of its node.
+func endpointWeight(a *address, nodeWeights map[string]int) int {
+	if w, ok := nodeWeights[a.Node]; ok {
+		return w
+	}
+
+	return 1
+}
+
+func formatEndpointsForSubsetAddresses(addresses []*address, port *port, protocol string, nodeWeights map[string]int) []string {
 	var result []string
 	for _, address := range addresses {
-		result = append(result, formatEndpoint(address, port, protocol))
+		ep := formatEndpoint(address, port, protocol)
+		for i := 0; i < endpointWeight(address, nodeWeights); i++ {
+			result = append(result, ep)
+		}
 	}
 
 	return result
 
 }
 
-func (ep endpoint) targetsByServicePort(protocol string, servicePort *servicePort) []string {
+func (ep endpoint) targetsByServicePort(protocol string, servicePort *servicePort, nodeWeights map[string]int) []string {
 	for _, s := range ep.Subsets {
 		// If only one port exists in the endpoint, use it
 		if len(s.Ports) == 1 {
-			return formatEndpointsForSubsetAddresses(s.Addresses, s.Ports[0], protocol)
+			return formatEndpointsForSubsetAddresses(s.Addresses, s.Ports[0], protocol, nodeWeights)
 		}
 
 		// Otherwise match port by name
 		for _, p := range s.Ports {
-			if p.Name != servicePort.Name {
+			if !strings.EqualFold(p.Name, servicePort.Name) {
 				continue
 			}
 
-			return formatEndpointsForSubsetAddresses(s.Addresses, p, protocol)
+			return formatEndpointsForSubsetAddresses(s.Addresses, p, protocol, nodeWeights)
 		}
 	}
 
 	return nil
 }
 
-func (ep endpoint) targetsByServiceTarget(protocol string, serviceTarget *definitions.BackendPort) []string {
+func (ep endpoint) targetsByServiceTarget(protocol string, serviceTarget *definitions.BackendPort, nodeWeights map[string]int) []string {
 	portName, named := serviceTarget.Value.(string)
 	portValue, byValue := serviceTarget.Value.(int)
 	for _, s := range ep.Subsets {
 		for _, p := range s.Ports {
-			if named && p.Name != portName || byValue && p.Port != portValue {
+			if named && !strings.EqualFold(p.Name, portName) || byValue && p.Port != portValue {
 				continue
 			}
 
-			var result []string
-			for _, a := range s.Addresses {
-				result = append(result, formatEndpoint(a, p, protocol))
-			}
-
-			return result
+			return formatEndpointsForSubsetAddresses(s.Addresses, p, protocol, nodeWeights)
 		}
 	}
 
 	return nil
 }
 
+// notReady returns the endpoint with the not ready addresses in place of the
+// ready ones.
+func (ep endpoint) notReady() endpoint {
+	subsets := make([]*subset, len(ep.Subsets))
+	for i, s := range ep.Subsets {
+		subsets[i] = &subset{Addresses: s.NotReadyAddresses, Ports: s.Ports}
+	}
+
+	return endpoint{Meta: ep.Meta, Subsets: subsets}
+}
+
+// allNotReady tells whether the endpoint has only not ready addresses.
+func (ep endpoint) allNotReady() bool {
+	var notReady bool
+	for _, s := range ep.Subsets {
+		if len(s.Addresses) > 0 {
+			return false
+		}
+
+		if len(s.NotReadyAddresses) > 0 {
+			notReady = true
+		}
+	}
+
+	return notReady
+}
+
 type subset struct {
-	Addresses []*address `json:"addresses"`
-	Ports     []*port    `json:"ports"`
+	Addresses         []*address `json:"addresses"`
+	NotReadyAddresses []*address `json:"notReadyAddresses"`
+	Ports             []*port    `json:"ports"`
 }
 
 type address struct {
-	IP   string `json:"ip"`
-	Node string `json:"nodeName"`
+	IP        string           `json:"ip"`
+	Node      string           `json:"nodeName"`
+	TargetRef *objectReference `json:"targetRef"`
+}
+
+type objectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type node struct {
+	Meta *definitions.Metadata `json:"metadata"`
+}
+
+type nodeList struct {
+	Items []*node `json:"items"`
 }
 
 type port struct {
@@ -160,8 +224,10 @@ func newResourceID(namespace, name string) definitions.ResourceID {
 
 type endpointID struct {
 	definitions.ResourceID
-	targetPort string
-	protocol   string
+	servicePort string
+	targetPort  string
+	protocol    string
+	selector    string
 }
 
 type ClusterResource struct {
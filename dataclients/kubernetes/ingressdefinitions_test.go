@@ -32,6 +32,26 @@ func TestMatchingPort(t *testing.T) {
 			targetPort: definitions.BackendPort{Value: "web"},
 			expected:   true,
 		},
+		{
+			name: "svc-name-mixed-case",
+			sp: &servicePort{
+				Name:       "HTTP",
+				Port:       80,
+				TargetPort: &definitions.BackendPort{Value: 5000},
+			},
+			targetPort: definitions.BackendPort{Value: "http"},
+			expected:   true,
+		},
+		{
+			name: "svc-name-not-matching",
+			sp: &servicePort{
+				Name:       "web",
+				Port:       80,
+				TargetPort: &definitions.BackendPort{Value: 5000},
+			},
+			targetPort: definitions.BackendPort{Value: "http"},
+			expected:   false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
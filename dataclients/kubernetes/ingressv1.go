@@ -39,7 +39,12 @@ func convertPathRuleV1(
 	host string,
 	prule *definitions.PathRuleV1,
 	pathMode PathMode,
+	hostPortRx string,
 	allowedExternalNames []*regexp.Regexp,
+	allowLocalExternalNames bool,
+	defaultLBAlgorithm string,
+	sel endpointSelector,
+	diag *diagnostics,
 ) (*eskip.Route, error) {
 
 	ns := metadata.Namespace
@@ -57,7 +62,7 @@ func convertPathRuleV1(
 
 	var hostRegexp []string
 	if host != "" {
-		hostRegexp = []string{createHostRx(host)}
+		hostRegexp = []string{createHostRxPort(hostPortRx, host)}
 	}
 	svcPort := prule.Backend.Service.Port
 	svcName := prule.Backend.Service.Name
@@ -71,22 +76,31 @@ func convertPathRuleV1(
 	servicePort, err := svc.getServicePortV1(svcPort)
 	if err != nil {
 		// service definition is wrong or no pods
+		if err == errServiceWithoutPorts {
+			diag.Errorf("Service %s has no ports", svcName)
+		}
+
 		err = nil
 		if len(eps) > 0 {
 			// should never happen
 			log.Errorf("convertPathRuleV1: Failed to find target port for service %s, but %d endpoints exist. Kubernetes has inconsistent data", svcName, len(eps))
 		}
 	} else if svc.Spec.Type == "ExternalName" {
-		return externalNameRoute(ns, name, host, hostRegexp, svc, servicePort, allowedExternalNames)
+		return externalNameRoute(ns, name, host, hostRegexp, svc, servicePort, allowedExternalNames, allowLocalExternalNames)
 	} else {
 		protocol := "http"
 		if p, ok := metadata.Annotations[skipperBackendProtocolAnnotationKey]; ok {
 			protocol = p
 		}
 
-		eps = state.getEndpointsByService(ns, svcName, protocol, servicePort)
+		eps = state.getEndpointsByService(ns, svcName, protocol, servicePort, sel)
 		log.Debugf("convertPathRuleV1: Found %d endpoints %s for %s", len(eps), servicePort, svcName)
 	}
+	if len(eps) == 0 && state.dropNotReady(ns, svcName) {
+		log.Debugf("convertPathRuleV1: all endpoints of service %s/%s are not ready", ns, svcName)
+		return nil, errAllEndpointsNotReady
+	}
+
 	if len(eps) == 0 {
 		// add shunt route https://github.com/zalando/skipper/issues/1525
 		log.Debugf("convertPathRuleV1: add shuntroute to return 502 for ingress %s/%s service %s with %d endpoints", ns, name, svcName, len(eps))
@@ -119,7 +133,7 @@ func convertPathRuleV1(
 		Id:          routeID(ns, name, host, prule.Path, svcName),
 		BackendType: eskip.LBBackend,
 		LBEndpoints: eps,
-		LBAlgorithm: getLoadBalancerAlgorithm(metadata),
+		LBAlgorithm: getLoadBalancerAlgorithm(metadata, defaultLBAlgorithm),
 		HostRegexps: hostRegexp,
 	}
 	setPathV1(pathMode, r, prule.PathType, prule.Path)
@@ -128,6 +142,10 @@ func convertPathRuleV1(
 }
 
 func (ing *ingress) addEndpointsRuleV1(ic ingressContext, host string, prule *definitions.PathRuleV1) error {
+	if prule.Backend != nil {
+		ic.checkBackendPort(prule.Backend.Service.Name, prule.Backend.Service.Port)
+	}
+
 	meta := ic.ingressV1.Metadata
 	endpointsRoute, err := convertPathRuleV1(
 		ic.state,
@@ -135,11 +153,16 @@ func (ing *ingress) addEndpointsRuleV1(ic ingressContext, host string, prule *de
 		host,
 		prule,
 		ic.pathMode,
+		ing.hostPortRx,
 		ing.allowedExternalNames,
+		ing.allowLocalExternalNames,
+		ing.defaultLBAlgorithm,
+		ic.endpointSelector,
+		ic.diagnostics,
 	)
 	if err != nil {
 		// if the service is not found the route should be removed
-		if err == errServiceNotFound || err == errResourceNotFound {
+		if err == errServiceNotFound || err == errResourceNotFound || err == errAllEndpointsNotReady {
 			return nil
 		}
 
@@ -155,26 +178,34 @@ func (ing *ingress) addEndpointsRuleV1(ic ingressContext, host string, prule *de
 		return fmt.Errorf("error while getting service: %v", err)
 	}
 
-	// safe prepend, see: https://play.golang.org/p/zg5aGKJpRyK
-	filters := make([]*eskip.Filter, len(endpointsRoute.Filters)+len(ic.annotationFilters))
-	copy(filters, ic.annotationFilters)
-	copy(filters[len(ic.annotationFilters):], endpointsRoute.Filters)
-	endpointsRoute.Filters = filters
-
-	// add pre-configured default filters
-	df, err := ic.defaultFilters.getNamed(meta.Namespace, prule.Backend.Service.Name)
-	if err != nil {
-		ic.logger.Errorf("Failed to retrieve default filters: %v.", err)
-	} else {
-		// it's safe to prepend, because type defaultFilters copies the slice during get()
-		endpointsRoute.Filters = append(df, endpointsRoute.Filters...)
+	if ic.fallbackService != nil {
+		ic.fallbackService.apply(endpointsRoute, ic.state, meta, ic.endpointSelector, ing.defaultLBAlgorithm)
 	}
 
-	err = applyAnnotationPredicates(ic.pathMode, endpointsRoute, ic.annotationPredicate)
-	if err != nil {
-		ic.logger.Errorf("failed to apply annotation predicates: %v", err)
+	ic.applyAnnotations(endpointsRoute, meta.Namespace, prule.Backend.Service.Name)
+	if ic.shadowEvery != nil {
+		endpointsRoute.Filters = append(endpointsRoute.Filters, ic.shadowEvery.filter())
 	}
 	ic.addHostRoute(host, endpointsRoute)
+	if ic.stickySession != nil && endpointsRoute.BackendType == eskip.LBBackend {
+		ic.addHostRoute(host, ic.stickySession.route(endpointsRoute))
+	}
+	// one fault injection route per path, from the route of the backend without a traffic split
+	if ic.faultInjection != nil && prule.Backend.Traffic >= 1.0 {
+		ic.addHostRoute(host, ic.faultInjection.route(endpointsRoute, ic.splitPredicates))
+	}
+	if ic.forceStatus != nil {
+		ic.addHostRoute(host, ic.forceStatus.route(endpointsRoute))
+	}
+	if ic.breakerBypass != nil {
+		ic.addHostRoute(host, breakerBypassRoute(endpointsRoute, ic.breakerBypass))
+	}
+	if ic.idempotentRetries {
+		ic.addHostRoute(host, nonIdempotentRoute(endpointsRoute))
+	}
+	if ic.allowedSource != nil {
+		ic.addHostRoute(host, deniedSourceRoute(endpointsRoute, ic.allowedSource))
+	}
 
 	redirect := ic.redirect
 	ewRangeMatch := false
@@ -201,8 +232,8 @@ func (ing *ingress) addEndpointsRuleV1(ic ingressContext, host string, prule *de
 	}
 
 	if ing.kubernetesEnableEastWest {
-		ewRoute := createEastWestRouteIng(ing.kubernetesEastWestDomain, meta.Name, meta.Namespace, endpointsRoute)
-		ewHost := fmt.Sprintf("%s.%s.%s", meta.Name, meta.Namespace, ing.kubernetesEastWestDomain)
+		ewRoute := createEastWestRouteIng(ing.eastWestHosts, ing.kubernetesEastWestDomain, meta.Name, meta.Namespace, endpointsRoute)
+		ewHost := ing.eastWestHosts.host(meta.Name, meta.Namespace, ing.kubernetesEastWestDomain)
 		ic.addHostRoute(ewHost, ewRoute)
 	}
 	return nil
@@ -291,6 +322,16 @@ func computeBackendWeightsV1(backendWeights map[string]float64, rule *definition
 	}
 }
 
+// roundBackendWeightsV1 rounds the traffic weights of the rule backends. The weight
+// of the last backend of a path is always 1.0, so the weights still add up.
+func roundBackendWeightsV1(rule *definitions.RuleV1, precision int) {
+	for _, path := range rule.Http.Paths {
+		if path.Backend.Traffic > 0 && path.Backend.Traffic < 1 {
+			path.Backend.Traffic = roundTraffic(path.Backend.Traffic, precision)
+		}
+	}
+}
+
 // TODO: default filters not applied to 'extra' routes from the custom route annotations. Is it on purpose?
 // https://github.com/zalando/skipper/issues/1287
 func (ing *ingress) addSpecRuleV1(ic ingressContext, ru *definitions.RuleV1) error {
@@ -298,20 +339,247 @@ func (ing *ingress) addSpecRuleV1(ic ingressContext, ru *definitions.RuleV1) err
 		ic.logger.Warn("invalid ingress item: rule missing http definitions")
 		return nil
 	}
+	if _, ok := stripHostPort(ru.Host); ok {
+		ic.diagnostics.Warnf("Invalid host with port: %s, ignoring the port", ru.Host)
+	}
+	if !ic.allowedHosts.allows(ru.Host) {
+		ic.logger.Warnf("Host %s is not allowed by the %s annotation, ignoring rule", ru.Host, skipperAllowedHostsAnnotationKey)
+		return nil
+	}
 	// update Traffic field for each backend
 	computeBackendWeightsV1(ic.backendWeights, ru)
+	if ing.backendWeightPrecision > 0 {
+		roundBackendWeightsV1(ru, ing.backendWeightPrecision)
+	}
+	cookiePaths := make(map[string]bool)
+	ipSplitPaths := make(map[string]bool)
+	shadowPaths := make(map[string]bool)
+	schedulePaths := make(map[string]bool)
+
+	// the most predicates used to split the traffic of a path between its backends
+	splitPredicates := make(map[string]int)
 	for _, prule := range ru.Http.Paths {
-		addExtraRoutes(ic, ru.Host, prule.Path, prule.PathType, ing.kubernetesEastWestDomain, ing.kubernetesEnableEastWest)
+		if n := splitPredicateCount(prule.Backend.Traffic, prule.Backend.NoopCount); n > splitPredicates[prule.PathType+prule.Path] {
+			splitPredicates[prule.PathType+prule.Path] = n
+		}
+	}
+
+	for _, prule := range ru.Http.Paths {
+		addExtraRoutes(ic, ru.Host, prule.Path, prule.PathType, ing.kubernetesEastWestDomain, ing.hostPortRx, ing.eastWestHosts, ing.kubernetesEnableEastWest)
+		ic.splitPredicates = splitPredicates[prule.PathType+prule.Path]
 		if prule.Backend.Traffic > 0 {
 			err := ing.addEndpointsRuleV1(ic, ru.Host, prule)
 			if err != nil {
 				return err
 			}
 		}
+
+		// one cookie route per path, even when the traffic is split between multiple backends
+		if ic.cookieRoute != nil && !cookiePaths[prule.PathType+prule.Path] {
+			cookiePaths[prule.PathType+prule.Path] = true
+			if err := ing.addCookieRouteV1(ic, ru.Host, prule); err != nil {
+				return err
+			}
+		}
+
+		if ic.ipSplit != nil && !ipSplitPaths[prule.PathType+prule.Path] {
+			ipSplitPaths[prule.PathType+prule.Path] = true
+			if err := ing.addIPSplitRouteV1(ic, ru.Host, prule); err != nil {
+				return err
+			}
+		}
+
+		if ic.shadowEvery != nil && !shadowPaths[prule.PathType+prule.Path] {
+			shadowPaths[prule.PathType+prule.Path] = true
+			if err := ing.addShadowRouteV1(ic, ru.Host, prule); err != nil {
+				return err
+			}
+		}
+
+		if ic.schedule != nil && !schedulePaths[prule.PathType+prule.Path] {
+			schedulePaths[prule.PathType+prule.Path] = true
+			if err := ing.addScheduleRoutesV1(ic, ru.Host, prule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addCanaryRouteV1 creates a route to the canary service for the host and path of
+// the path rule, with the additional predicate selecting the canary requests. Having
+// the additional predicate, and a True predicate for each predicate used to split the
+// traffic of the path, the route takes precedence over all the routes of the path
+// rule. The kind of the canary route is used in the route ID and in the logs.
+func (ing *ingress) addCanaryRouteV1(
+	ic ingressContext,
+	host string,
+	prule *definitions.PathRuleV1,
+	kind string,
+	service string,
+	port definitions.BackendPortV1,
+	p *eskip.Predicate,
+) error {
+	meta := ic.ingressV1.Metadata
+	canaryRule := &definitions.PathRuleV1{
+		Path:     prule.Path,
+		PathType: prule.PathType,
+		Backend: &definitions.BackendV1{
+			Service: definitions.Service{
+				Name: service,
+				Port: port,
+			},
+		},
+	}
+
+	r, err := convertPathRuleV1(ic.state, meta, host, canaryRule, ic.pathMode, ing.hostPortRx, ing.allowedExternalNames, ing.allowLocalExternalNames, ing.defaultLBAlgorithm, ic.endpointSelector, ic.diagnostics)
+	if err != nil {
+		if err == errServiceNotFound || err == errResourceNotFound {
+			ic.logger.Errorf("Failed to find the service of the %s route: %s", kind, service)
+			return nil
+		}
+
+		if err == errAllEndpointsNotReady {
+			return nil
+		}
+
+		if errors.Is(err, errNotAllowedExternalName) {
+			log.Infof("Not allowed external name: %v", err)
+			return nil
+		}
+
+		return fmt.Errorf("error while getting service of the %s route: %w", kind, err)
+	}
+
+	r.Id = routeID(meta.Namespace, meta.Name, host, prule.Path, service+"_"+kind)
+	ic.applyAnnotations(r, meta.Namespace, service)
+	r.Predicates = append(r.Predicates, p)
+	r.Predicates = append(r.Predicates, truePredicates(ic.splitPredicates)...)
+	ic.addHostRoute(host, r)
+
+	if ing.kubernetesEnableEastWest {
+		ewRoute := createEastWestRouteIng(ing.eastWestHosts, ing.kubernetesEastWestDomain, meta.Name, meta.Namespace, r)
+		ewHost := ing.eastWestHosts.host(meta.Name, meta.Namespace, ing.kubernetesEastWestDomain)
+		ic.addHostRoute(ewHost, ewRoute)
 	}
+
 	return nil
 }
 
+// normalizePrefixPathsV1 replaces the empty paths having the Prefix path type
+// with "/". While these paths are invalid, they are common, and the expected
+// behavior is to match all the paths.
+func normalizePrefixPathsV1(ic ingressContext, ru *definitions.RuleV1) {
+	if ru.Http == nil {
+		return
+	}
+
+	for _, prule := range ru.Http.Paths {
+		if prule.PathType == "Prefix" && prule.Path == "" {
+			ic.logger.Warnf("Empty path with Prefix path type in the rule of host %s, using /", ru.Host)
+			prule.Path = "/"
+		}
+	}
+}
+
+// normalizeLeadingSlashV1 prepends "/" to the paths without a leading slash,
+// because the routes created for them would never match the request paths. It
+// is enabled by the NormalizePaths option, and it doesn't apply to the paths
+// used as regular expressions in the PathRegexp path mode.
+func (ing *ingress) normalizeLeadingSlashV1(ic ingressContext, ru *definitions.RuleV1) {
+	if !ing.normalizePaths || ru.Http == nil {
+		return
+	}
+
+	for _, prule := range ru.Http.Paths {
+		if prule.PathType != "Exact" && prule.PathType != "Prefix" && ic.pathMode == PathRegexp {
+			continue
+		}
+
+		if prule.Path != "" && !strings.HasPrefix(prule.Path, "/") {
+			ic.logger.Warnf("Path %s without a leading slash in the rule of host %s, using /%s", prule.Path, ru.Host, prule.Path)
+			prule.Path = "/" + prule.Path
+		}
+	}
+}
+
+// removeDuplicatePathsV1 removes the duplicate paths of a rule, keeping the
+// last one. Paths are duplicates when they have the same path, path type and
+// backend service, because the routes created for them would have the same ID.
+// It returns false when the rule has duplicate paths, and they are configured
+// to be rejected.
+func (ing *ingress) removeDuplicatePathsV1(ic ingressContext, ru *definitions.RuleV1) bool {
+	if ru.Http == nil {
+		return true
+	}
+
+	type pathKey struct {
+		pathType, path, service string
+	}
+
+	keyOf := func(prule *definitions.PathRuleV1) pathKey {
+		k := pathKey{pathType: prule.PathType, path: prule.Path}
+		if prule.Backend != nil {
+			k.service = prule.Backend.Service.Name
+		}
+
+		return k
+	}
+
+	last := make(map[pathKey]int)
+	for idx, prule := range ru.Http.Paths {
+		last[keyOf(prule)] = idx
+	}
+
+	if len(last) == len(ru.Http.Paths) {
+		return true
+	}
+
+	if ing.rejectDuplicatePaths {
+		ic.logger.Errorf("Skipping ingress, duplicate paths in the rule of host %s", ru.Host)
+		return false
+	}
+
+	paths := make([]*definitions.PathRuleV1, 0, len(last))
+	for idx, prule := range ru.Http.Paths {
+		if last[keyOf(prule)] != idx {
+			ic.logger.Warnf("Duplicate path %s in the rule of host %s, using the last one", prule.Path, ru.Host)
+			continue
+		}
+
+		paths = append(paths, prule)
+	}
+
+	ru.Http.Paths = paths
+	return true
+}
+
+// hasIngressTLSV1 reports whether any of the ingresses has TLS entries.
+func hasIngressTLSV1(ingresses []*definitions.IngressV1Item) bool {
+	for _, i := range ingresses {
+		if i.Spec != nil && len(i.Spec.IngressTLS) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateTLSSecretV1 reports the TLS entry, when the secret referenced by it doesn't exist. It
+// doesn't affect the conversion of the ingress. When the secrets could not be loaded, the entry
+// is not validated.
+func (ing *ingress) validateTLSSecretV1(ic ingressContext, ingtls *definitions.TLSV1) {
+	if ic.state.secrets == nil {
+		return
+	}
+
+	// Secrets should always reside in same namespace as the Ingress
+	secretID := definitions.ResourceID{Name: ingtls.SecretName, Namespace: ic.ingressV1.Metadata.Namespace}
+	if _, ok := ic.state.secrets[secretID]; !ok {
+		ic.diagnostics.Errorf("TLS secret %s not found in namespace %s, skipping TLS hosts %s", secretID.Name, secretID.Namespace, strings.Join(ingtls.Hosts, ", "))
+	}
+}
+
 // addSpecIngressTLSV1 is used to add TLS Certificates from Ingress resources. Certificates will be added
 // only if the Ingress rule host matches a host in TLS config
 func (ing *ingress) addSpecIngressTLSV1(ic ingressContext, ingtls *definitions.TLSV1) {
@@ -330,6 +598,8 @@ func (ing *ingress) addSpecIngressTLSV1(ic ingressContext, ingtls *definitions.T
 func (ing *ingress) convertDefaultBackendV1(
 	state *clusterState,
 	i *definitions.IngressV1Item,
+	sel endpointSelector,
+	diag *diagnostics,
 ) (*eskip.Route, bool, error) {
 	// the usage of the default backend depends on what we want
 	// we can generate a hostname out of it based on shared rules
@@ -359,10 +629,14 @@ func (ing *ingress) convertDefaultBackendV1(
 
 	servicePort, err := svc.getServicePortV1(svcPort)
 	if err != nil {
+		if err == errServiceWithoutPorts {
+			diag.Errorf("Service %s has no ports", svcName)
+		}
+
 		log.Errorf("convertDefaultBackendV1: Failed to find target port %v, %s, for ingress %s/%s and service %s add shuntroute: %v", svc.Spec.Ports, svcPort, ns, name, svcName, err)
 		err = nil
 	} else if svc.Spec.Type == "ExternalName" {
-		r, err := externalNameRoute(ns, name, "default", nil, svc, servicePort, ing.allowedExternalNames)
+		r, err := externalNameRoute(ns, name, "default", nil, svc, servicePort, ing.allowedExternalNames, ing.allowLocalExternalNames)
 		return r, err == nil, err
 	} else {
 		log.Debugf("convertDefaultBackendV1: Found target port %v, for service %s", servicePort.TargetPort, svcName)
@@ -376,6 +650,7 @@ func (ing *ingress) convertDefaultBackendV1(
 			svcName,
 			protocol,
 			servicePort,
+			sel,
 		)
 		log.Debugf("convertDefaultBackendV1: Found %d endpoints for %s: %v", len(eps), svcName, err)
 	}
@@ -400,7 +675,7 @@ func (ing *ingress) convertDefaultBackendV1(
 		Id:          routeID(ns, name, "", "", ""),
 		BackendType: eskip.LBBackend,
 		LBEndpoints: eps,
-		LBAlgorithm: getLoadBalancerAlgorithm(i.Metadata),
+		LBAlgorithm: getLoadBalancerAlgorithm(i.Metadata, ing.defaultLBAlgorithm),
 	}, true, nil
 }
 
@@ -409,9 +684,11 @@ func (ing *ingress) ingressV1Route(
 	redirect *redirectInfo,
 	state *clusterState,
 	hostRoutes map[string][]*eskip.Route,
+	routeOwners map[string]definitions.ResourceID,
 	df defaultFilters,
 	r *certregistry.CertRegistry,
-) (*eskip.Route, error) {
+	hc hostCerts,
+) (*defaultBackendRoute, error) {
 	if i.Metadata == nil || i.Metadata.Namespace == "" || i.Metadata.Name == "" || i.Spec == nil {
 		log.Error("invalid ingress item: missing Metadata or Spec")
 		return nil, nil
@@ -419,38 +696,92 @@ func (ing *ingress) ingressV1Route(
 	logger := log.WithFields(log.Fields{
 		"ingress": fmt.Sprintf("%s/%s", i.Metadata.Namespace, i.Metadata.Name),
 	})
+	annotationFilters, ok := ing.ingressAnnotationFilters(i.Metadata, logger)
+	if !ok {
+		return nil, nil
+	}
+
 	redirect.initCurrent(i.Metadata)
 	ic := ingressContext{
 		state:               state,
 		ingressV1:           i,
 		logger:              logger,
-		annotationFilters:   annotationFilter(i.Metadata, logger),
+		diagnostics:         newDiagnostics(logger),
+		annotationFilters:   annotationFilters,
 		annotationPredicate: annotationPredicate(i.Metadata),
 		extraRoutes:         extraRoutes(i.Metadata, logger),
 		backendWeights:      backendWeights(i.Metadata, logger),
+		faultInjection:      faultInjectionAnnotation(i.Metadata, logger),
+		forceStatus:         forceStatusAnnotation(i.Metadata, logger),
+		fallbackService:     fallbackServiceAnnotation(i.Metadata, logger),
+		endpointSelector:    endpointSelectorAnnotation(i.Metadata, logger),
+		breakerBypass:       ing.breakerBypassSource(i.Metadata, logger),
+		idempotentRetries:   idempotentRetriesAnnotation(i.Metadata, logger),
+		backendHTTP1:        backendHTTP1FilterV1(i, state, logger),
+		priorityWeight:      priorityWeight(i.Metadata, logger),
+		clientCert:          clientCertPredicate(i.Metadata, logger),
+		allowedSource:       ing.allowedSource(i.Metadata, logger),
+		cookieRoute:         cookieRouteAnnotation(i.Metadata, logger),
+		ipSplit:             ipSplitAnnotation(i.Metadata, logger),
+		shadowEvery:         shadowEveryAnnotation(i.Metadata, logger),
+		schedule:            scheduleAnnotation(i.Metadata, logger),
+		allowedHosts:        allowedHostsAnnotation(i.Metadata, ing.hostPortRx, logger),
+		stickySession:       stickySessionAnnotation(i.Metadata, logger),
+		securityHeaders:     ing.securityHeaderFilters(i.Metadata, logger),
 		pathMode:            pathMode(i.Metadata, ing.pathMode),
 		redirect:            redirect,
 		hostRoutes:          hostRoutes,
+		routeOwners:         routeOwners,
 		defaultFilters:      df,
 		certificateRegistry: r,
+		hostCerts:           hc,
+	}
+
+	for _, rule := range i.Spec.Rules {
+		normalizePrefixPathsV1(ic, rule)
+		ing.normalizeLeadingSlashV1(ic, rule)
+		if !ing.removeDuplicatePathsV1(ic, rule) {
+			return nil, nil
+		}
 	}
 
 	var route *eskip.Route
-	if r, ok, err := ing.convertDefaultBackendV1(state, i); ok {
+	if r, ok, err := ing.convertDefaultBackendV1(state, i, ic.endpointSelector, ic.diagnostics); ok {
+		ic.checkBackendPort(i.Spec.DefaultBackend.Service.Name, i.Spec.DefaultBackend.Service.Port)
+		ic.applyAnnotations(r, i.Metadata.Namespace, i.Spec.DefaultBackend.Service.Name)
 		route = r
 	} else if err != nil {
 		ic.logger.Errorf("error while converting default backend: %v", err)
 	}
-	for _, rule := range i.Spec.Rules {
+	hosts := make([]string, len(i.Spec.Rules))
+	for idx, rule := range i.Spec.Rules {
+		hosts[idx] = rule.Host
+	}
+
+	weights := ruleWeights(hosts)
+	for idx, rule := range i.Spec.Rules {
+		ic.ruleWeight = weights[idx]
 		err := ing.addSpecRuleV1(ic, rule)
 		if err != nil {
 			return nil, err
 		}
 	}
+	for _, ingtls := range i.Spec.IngressTLS {
+		ing.validateTLSSecretV1(ic, ingtls)
+	}
 	if ic.certificateRegistry != nil {
 		for _, ingtls := range i.Spec.IngressTLS {
 			ing.addSpecIngressTLSV1(ic, ingtls)
 		}
 	}
-	return route, nil
+	if route == nil {
+		return nil, nil
+	}
+
+	return &defaultBackendRoute{
+		route:       route,
+		owner:       i.Metadata.ToResourceID(),
+		hosts:       ingressRuleHostsV1(i.Spec.Rules),
+		forceStatus: ic.forceStatus,
+	}, nil
 }
@@ -39,7 +39,12 @@ func convertPathRule(
 	host string,
 	prule *definitions.PathRule,
 	pathMode PathMode,
+	hostPortRx string,
 	allowedExternalNames []*regexp.Regexp,
+	allowLocalExternalNames bool,
+	defaultLBAlgorithm string,
+	sel endpointSelector,
+	diag *diagnostics,
 ) (*eskip.Route, error) {
 
 	ns := metadata.Namespace
@@ -57,7 +62,7 @@ func convertPathRule(
 
 	var hostRegexp []string
 	if host != "" {
-		hostRegexp = []string{createHostRx(host)}
+		hostRegexp = []string{createHostRxPort(hostPortRx, host)}
 	}
 	svcPort := prule.Backend.ServicePort
 	svcName := prule.Backend.ServiceName
@@ -71,22 +76,31 @@ func convertPathRule(
 	servicePort, err := svc.getServicePort(svcPort)
 	if err != nil {
 		// service definition is wrong or no pods
+		if err == errServiceWithoutPorts {
+			diag.Errorf("Service %s has no ports", svcName)
+		}
+
 		err = nil
 		if len(eps) > 0 {
 			// should never happen
 			log.Errorf("convertPathRule: Failed to find target port for service %s, but %d endpoints exist. Kubernetes has inconsistent data", svcName, len(eps))
 		}
 	} else if svc.Spec.Type == "ExternalName" {
-		return externalNameRoute(ns, name, host, hostRegexp, svc, servicePort, allowedExternalNames)
+		return externalNameRoute(ns, name, host, hostRegexp, svc, servicePort, allowedExternalNames, allowLocalExternalNames)
 	} else {
 		protocol := "http"
 		if p, ok := metadata.Annotations[skipperBackendProtocolAnnotationKey]; ok {
 			protocol = p
 		}
 
-		eps = state.getEndpointsByService(ns, svcName, protocol, servicePort)
+		eps = state.getEndpointsByService(ns, svcName, protocol, servicePort, sel)
 		log.Debugf("convertPathRule: Found %d endpoints %s for %s", len(eps), servicePort, svcName)
 	}
+	if len(eps) == 0 && state.dropNotReady(ns, svcName) {
+		log.Debugf("convertPathRule: all endpoints of service %s/%s are not ready", ns, svcName)
+		return nil, errAllEndpointsNotReady
+	}
+
 	if len(eps) == 0 {
 		// add shunt route https://github.com/zalando/skipper/issues/1525
 		log.Debugf("convertPathRule: add shuntroute to return 502 for ingress %s/%s service %s with %d endpoints", ns, name, svcName, len(eps))
@@ -118,7 +132,7 @@ func convertPathRule(
 		Id:          routeID(ns, name, host, prule.Path, svcName),
 		BackendType: eskip.LBBackend,
 		LBEndpoints: eps,
-		LBAlgorithm: getLoadBalancerAlgorithm(metadata),
+		LBAlgorithm: getLoadBalancerAlgorithm(metadata, defaultLBAlgorithm),
 		HostRegexps: hostRegexp,
 	}
 	setPath(pathMode, r, prule.Path)
@@ -127,6 +141,10 @@ func convertPathRule(
 }
 
 func (ing *ingress) addEndpointsRule(ic ingressContext, host string, prule *definitions.PathRule) error {
+	if prule.Backend != nil {
+		ic.checkBackendPort(prule.Backend.ServiceName, prule.Backend.ServicePort)
+	}
+
 	meta := ic.ingress.Metadata
 	endpointsRoute, err := convertPathRule(
 		ic.state,
@@ -134,11 +152,16 @@ func (ing *ingress) addEndpointsRule(ic ingressContext, host string, prule *defi
 		host,
 		prule,
 		ic.pathMode,
+		ing.hostPortRx,
 		ing.allowedExternalNames,
+		ing.allowLocalExternalNames,
+		ing.defaultLBAlgorithm,
+		ic.endpointSelector,
+		ic.diagnostics,
 	)
 	if err != nil {
 		// if the service is not found the route should be removed
-		if err == errServiceNotFound || err == errResourceNotFound {
+		if err == errServiceNotFound || err == errResourceNotFound || err == errAllEndpointsNotReady {
 			return nil
 		}
 
@@ -154,26 +177,31 @@ func (ing *ingress) addEndpointsRule(ic ingressContext, host string, prule *defi
 		return fmt.Errorf("error while getting service: %v", err)
 	}
 
-	// safe prepend, see: https://play.golang.org/p/zg5aGKJpRyK
-	filters := make([]*eskip.Filter, len(endpointsRoute.Filters)+len(ic.annotationFilters))
-	copy(filters, ic.annotationFilters)
-	copy(filters[len(ic.annotationFilters):], endpointsRoute.Filters)
-	endpointsRoute.Filters = filters
-
-	// add pre-configured default filters
-	df, err := ic.defaultFilters.getNamed(meta.Namespace, prule.Backend.ServiceName)
-	if err != nil {
-		ic.logger.Errorf("Failed to retrieve default filters: %v.", err)
-	} else {
-		// it's safe to prepend, because type defaultFilters copies the slice during get()
-		endpointsRoute.Filters = append(df, endpointsRoute.Filters...)
+	if ic.fallbackService != nil {
+		ic.fallbackService.apply(endpointsRoute, ic.state, meta, ic.endpointSelector, ing.defaultLBAlgorithm)
 	}
 
-	err = applyAnnotationPredicates(ic.pathMode, endpointsRoute, ic.annotationPredicate)
-	if err != nil {
-		ic.logger.Errorf("failed to apply annotation predicates: %v", err)
-	}
+	ic.applyAnnotations(endpointsRoute, meta.Namespace, prule.Backend.ServiceName)
 	ic.addHostRoute(host, endpointsRoute)
+	if ic.stickySession != nil && endpointsRoute.BackendType == eskip.LBBackend {
+		ic.addHostRoute(host, ic.stickySession.route(endpointsRoute))
+	}
+	// one fault injection route per path, from the route of the backend without a traffic split
+	if ic.faultInjection != nil && prule.Backend.Traffic >= 1.0 {
+		ic.addHostRoute(host, ic.faultInjection.route(endpointsRoute, ic.splitPredicates))
+	}
+	if ic.forceStatus != nil {
+		ic.addHostRoute(host, ic.forceStatus.route(endpointsRoute))
+	}
+	if ic.breakerBypass != nil {
+		ic.addHostRoute(host, breakerBypassRoute(endpointsRoute, ic.breakerBypass))
+	}
+	if ic.idempotentRetries {
+		ic.addHostRoute(host, nonIdempotentRoute(endpointsRoute))
+	}
+	if ic.allowedSource != nil {
+		ic.addHostRoute(host, deniedSourceRoute(endpointsRoute, ic.allowedSource))
+	}
 
 	redirect := ic.redirect
 	ewRangeMatch := false
@@ -200,8 +228,8 @@ func (ing *ingress) addEndpointsRule(ic ingressContext, host string, prule *defi
 	}
 
 	if ing.kubernetesEnableEastWest {
-		ewRoute := createEastWestRouteIng(ing.kubernetesEastWestDomain, meta.Name, meta.Namespace, endpointsRoute)
-		ewHost := fmt.Sprintf("%s.%s.%s", meta.Name, meta.Namespace, ing.kubernetesEastWestDomain)
+		ewRoute := createEastWestRouteIng(ing.eastWestHosts, ing.kubernetesEastWestDomain, meta.Name, meta.Namespace, endpointsRoute)
+		ewHost := ing.eastWestHosts.host(meta.Name, meta.Namespace, ing.kubernetesEastWestDomain)
 		ic.addHostRoute(ewHost, ewRoute)
 	}
 	return nil
@@ -290,6 +318,31 @@ func computeBackendWeights(backendWeights map[string]float64, rule *definitions.
 	}
 }
 
+// roundBackendWeights rounds the traffic weights of the rule backends. The weight
+// of the last backend of a path is always 1.0, so the weights still add up.
+func roundBackendWeights(rule *definitions.Rule, precision int) {
+	for _, path := range rule.Http.Paths {
+		if path.Backend.Traffic > 0 && path.Backend.Traffic < 1 {
+			path.Backend.Traffic = roundTraffic(path.Backend.Traffic, precision)
+		}
+	}
+}
+
+// normalizeLeadingSlash prepends "/" to the paths without a leading slash,
+// when the NormalizePaths option is enabled. See normalizeLeadingSlashV1.
+func (ing *ingress) normalizeLeadingSlash(ic ingressContext, ru *definitions.Rule) {
+	if !ing.normalizePaths || ru.Http == nil || ic.pathMode == PathRegexp {
+		return
+	}
+
+	for _, prule := range ru.Http.Paths {
+		if prule.Path != "" && !strings.HasPrefix(prule.Path, "/") {
+			ic.logger.Warnf("Path %s without a leading slash in the rule of host %s, using /%s", prule.Path, ru.Host, prule.Path)
+			prule.Path = "/" + prule.Path
+		}
+	}
+}
+
 // TODO: default filters not applied to 'extra' routes from the custom route annotations. Is it on purpose?
 // https://github.com/zalando/skipper/issues/1287
 func (ing *ingress) addSpecRule(ic ingressContext, ru *definitions.Rule) error {
@@ -297,11 +350,31 @@ func (ing *ingress) addSpecRule(ic ingressContext, ru *definitions.Rule) error {
 		ic.logger.Warn("invalid ingress item: rule missing http definitions")
 		return nil
 	}
+	if _, ok := stripHostPort(ru.Host); ok {
+		ic.diagnostics.Warnf("Invalid host with port: %s, ignoring the port", ru.Host)
+	}
+	if !ic.allowedHosts.allows(ru.Host) {
+		ic.logger.Warnf("Host %s is not allowed by the %s annotation, ignoring rule", ru.Host, skipperAllowedHostsAnnotationKey)
+		return nil
+	}
 	// update Traffic field for each backend
 	computeBackendWeights(ic.backendWeights, ru)
+	if ing.backendWeightPrecision > 0 {
+		roundBackendWeights(ru, ing.backendWeightPrecision)
+	}
+
+	// the most predicates used to split the traffic of a path between its backends
+	splitPredicates := make(map[string]int)
 	for _, prule := range ru.Http.Paths {
-		addExtraRoutes(ic, ru.Host, prule.Path, "ImplementationSpecific", ing.kubernetesEastWestDomain, ing.kubernetesEnableEastWest)
+		if n := splitPredicateCount(prule.Backend.Traffic, prule.Backend.NoopCount); n > splitPredicates[prule.Path] {
+			splitPredicates[prule.Path] = n
+		}
+	}
+
+	for _, prule := range ru.Http.Paths {
+		addExtraRoutes(ic, ru.Host, prule.Path, "ImplementationSpecific", ing.kubernetesEastWestDomain, ing.hostPortRx, ing.eastWestHosts, ing.kubernetesEnableEastWest)
 		if prule.Backend.Traffic > 0 {
+			ic.splitPredicates = splitPredicates[prule.Path]
 			err := ing.addEndpointsRule(ic, ru.Host, prule)
 			if err != nil {
 				return err
@@ -315,6 +388,8 @@ func (ing *ingress) addSpecRule(ic ingressContext, ru *definitions.Rule) error {
 func (ing *ingress) convertDefaultBackend(
 	state *clusterState,
 	i *definitions.IngressItem,
+	sel endpointSelector,
+	diag *diagnostics,
 ) (*eskip.Route, bool, error) {
 	// the usage of the default backend depends on what we want
 	// we can generate a hostname out of it based on shared rules
@@ -344,10 +419,14 @@ func (ing *ingress) convertDefaultBackend(
 
 	servicePort, err := svc.getServicePort(svcPort)
 	if err != nil {
+		if err == errServiceWithoutPorts {
+			diag.Errorf("Service %s has no ports", svcName)
+		}
+
 		log.Errorf("convertDefaultBackend: Failed to find target port %v, %s, for ingress %s/%s and service %s add shuntroute: %v", svc.Spec.Ports, svcPort, ns, name, svcName, err)
 		err = nil
 	} else if svc.Spec.Type == "ExternalName" {
-		r, err := externalNameRoute(ns, name, "default", nil, svc, servicePort, ing.allowedExternalNames)
+		r, err := externalNameRoute(ns, name, "default", nil, svc, servicePort, ing.allowedExternalNames, ing.allowLocalExternalNames)
 		return r, err == nil, err
 	} else {
 		log.Debugf("convertDefaultBackend: Found target port %v, for service %s", servicePort.TargetPort, svcName)
@@ -361,6 +440,7 @@ func (ing *ingress) convertDefaultBackend(
 			svcName,
 			protocol,
 			servicePort,
+			sel,
 		)
 		log.Debugf("convertDefaultBackend: Found %d endpoints for %s: %v", len(eps), svcName, err)
 	}
@@ -385,17 +465,36 @@ func (ing *ingress) convertDefaultBackend(
 		Id:          routeID(ns, name, "", "", ""),
 		BackendType: eskip.LBBackend,
 		LBEndpoints: eps,
-		LBAlgorithm: getLoadBalancerAlgorithm(i.Metadata),
+		LBAlgorithm: getLoadBalancerAlgorithm(i.Metadata, ing.defaultLBAlgorithm),
 	}, true, nil
 }
 
+// the annotations creating canary routes, supported only by the v1 ingresses
+var ingressV1OnlyAnnotations = []string{
+	skipperCookieRouteAnnotationKey,
+	skipperIPSplitAnnotationKey,
+	skipperShadowEveryAnnotationKey,
+	skipperScheduleAnnotationKey,
+}
+
+// log the annotations of the ingress that are ignored, because they are
+// supported only by the v1 ingresses
+func logIngressV1OnlyAnnotations(m *definitions.Metadata, logger *log.Entry) {
+	for _, key := range ingressV1OnlyAnnotations {
+		if _, ok := m.Annotations[key]; ok {
+			logger.Warnf("The %s annotation is supported only for the v1 ingresses, ignoring it", key)
+		}
+	}
+}
+
 func (ing *ingress) ingressRoute(
 	i *definitions.IngressItem,
 	redirect *redirectInfo,
 	state *clusterState,
 	hostRoutes map[string][]*eskip.Route,
+	routeOwners map[string]definitions.ResourceID,
 	df defaultFilters,
-) (*eskip.Route, error) {
+) (*defaultBackendRoute, error) {
 	if i.Metadata == nil || i.Metadata.Namespace == "" || i.Metadata.Name == "" || i.Spec == nil {
 		log.Error("invalid ingress item: missing Metadata or Spec")
 		return nil, nil
@@ -403,32 +502,75 @@ func (ing *ingress) ingressRoute(
 	logger := log.WithFields(log.Fields{
 		"ingress": fmt.Sprintf("%s/%s", i.Metadata.Namespace, i.Metadata.Name),
 	})
+	annotationFilters, ok := ing.ingressAnnotationFilters(i.Metadata, logger)
+	if !ok {
+		return nil, nil
+	}
+
+	logIngressV1OnlyAnnotations(i.Metadata, logger)
 	redirect.initCurrent(i.Metadata)
 	ic := ingressContext{
 		state:               state,
 		ingress:             i,
 		logger:              logger,
-		annotationFilters:   annotationFilter(i.Metadata, logger),
+		diagnostics:         newDiagnostics(logger),
+		annotationFilters:   annotationFilters,
 		annotationPredicate: annotationPredicate(i.Metadata),
 		extraRoutes:         extraRoutes(i.Metadata, logger),
 		backendWeights:      backendWeights(i.Metadata, logger),
+		faultInjection:      faultInjectionAnnotation(i.Metadata, logger),
+		forceStatus:         forceStatusAnnotation(i.Metadata, logger),
+		fallbackService:     fallbackServiceAnnotation(i.Metadata, logger),
+		endpointSelector:    endpointSelectorAnnotation(i.Metadata, logger),
+		breakerBypass:       ing.breakerBypassSource(i.Metadata, logger),
+		idempotentRetries:   idempotentRetriesAnnotation(i.Metadata, logger),
+		backendHTTP1:        backendHTTP1Filter(i, state, logger),
+		priorityWeight:      priorityWeight(i.Metadata, logger),
+		clientCert:          clientCertPredicate(i.Metadata, logger),
+		allowedSource:       ing.allowedSource(i.Metadata, logger),
+		allowedHosts:        allowedHostsAnnotation(i.Metadata, ing.hostPortRx, logger),
+		stickySession:       stickySessionAnnotation(i.Metadata, logger),
+		securityHeaders:     ing.securityHeaderFilters(i.Metadata, logger),
 		pathMode:            pathMode(i.Metadata, ing.pathMode),
 		redirect:            redirect,
 		hostRoutes:          hostRoutes,
+		routeOwners:         routeOwners,
 		defaultFilters:      df,
 	}
 
+	for _, rule := range i.Spec.Rules {
+		ing.normalizeLeadingSlash(ic, rule)
+	}
+
 	var route *eskip.Route
-	if r, ok, err := ing.convertDefaultBackend(state, i); ok {
+	if r, ok, err := ing.convertDefaultBackend(state, i, ic.endpointSelector, ic.diagnostics); ok {
+		ic.checkBackendPort(i.Spec.DefaultBackend.ServiceName, i.Spec.DefaultBackend.ServicePort)
+		ic.applyAnnotations(r, i.Metadata.Namespace, i.Spec.DefaultBackend.ServiceName)
 		route = r
 	} else if err != nil {
 		ic.logger.Errorf("error while converting default backend: %v", err)
 	}
-	for _, rule := range i.Spec.Rules {
+	hosts := make([]string, len(i.Spec.Rules))
+	for idx, rule := range i.Spec.Rules {
+		hosts[idx] = rule.Host
+	}
+
+	weights := ruleWeights(hosts)
+	for idx, rule := range i.Spec.Rules {
+		ic.ruleWeight = weights[idx]
 		err := ing.addSpecRule(ic, rule)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return route, nil
+	if route == nil {
+		return nil, nil
+	}
+
+	return &defaultBackendRoute{
+		route:       route,
+		owner:       i.Metadata.ToResourceID(),
+		hosts:       ingressRuleHosts(i.Spec.Rules),
+		forceStatus: ic.forceStatus,
+	}, nil
 }
@@ -0,0 +1,27 @@
+package kubernetes
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+const skipperInspectAnnotationKey = "zalando.org/skipper-inspect"
+
+// inspectionFilters returns the filters configured by the InspectionFilter
+// option, when the ingress enables them with the zalando.org/skipper-inspect
+// annotation.
+func (ing *ingress) inspectionFilters(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+	switch val, ok := m.Annotations[skipperInspectAnnotationKey]; {
+	case !ok || val == "false":
+		return nil
+	case val != "true":
+		logger.Errorf("Invalid %s annotation, true or false expected: %s", skipperInspectAnnotationKey, val)
+		return nil
+	case len(ing.inspectionFilter) == 0:
+		logger.Errorf("Invalid %s annotation, no inspection filter configured", skipperInspectAnnotationKey)
+		return nil
+	}
+
+	return eskip.CopyFilters(ing.inspectionFilter)
+}
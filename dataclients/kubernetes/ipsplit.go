@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperIPSplitAnnotationKey = "zalando.org/skipper-ip-split"
+
+// ipSplit is the configuration of the source IP based canary routing,
+// defined by the zalando.org/skipper-ip-split annotation. Requests from
+// a consistent ratio of the source IPs are routed to the canary service,
+// instead of the backends defined by the ingress rules.
+type ipSplit struct {
+	Ratio   float64 `json:"ratio"`
+	Service string  `json:"service"`
+	Port    string  `json:"port"`
+}
+
+// parse IP split annotation
+func ipSplitAnnotation(m *definitions.Metadata, logger *log.Entry) *ipSplit {
+	val, ok := m.Annotations[skipperIPSplitAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var s ipSplit
+	if err := json.Unmarshal([]byte(val), &s); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperIPSplitAnnotationKey, err)
+		return nil
+	}
+
+	if s.Service == "" || s.Port == "" {
+		logger.Errorf("invalid %s annotation, service and port are required", skipperIPSplitAnnotationKey)
+		return nil
+	}
+
+	if s.Ratio <= 0 || s.Ratio > 1 {
+		logger.Errorf("invalid %s annotation, ratio must be greater than 0 and at most 1: %v", skipperIPSplitAnnotationKey, s.Ratio)
+		return nil
+	}
+
+	return &s
+}
+
+func (s *ipSplit) backendPort() definitions.BackendPortV1 {
+	if n, err := strconv.Atoi(s.Port); err == nil {
+		return definitions.BackendPortV1{Number: n}
+	}
+
+	return definitions.BackendPortV1{Name: s.Port}
+}
+
+func (s *ipSplit) predicate(reverseSourcePredicate bool) *eskip.Predicate {
+	name := predicates.SourceSplitName
+	if reverseSourcePredicate {
+		name = predicates.SourceSplitFromLastName
+	}
+
+	return &eskip.Predicate{
+		Name: name,
+		Args: []interface{}{s.Ratio},
+	}
+}
+
+// addIPSplitRouteV1 creates a route to the canary service for the host and path
+// of the path rule, matching the configured ratio of the source IPs. The route
+// precedes the routes of the path rule, also when they split the traffic
+// between weighted backends, so the ratio applies to all the source IPs.
+func (ing *ingress) addIPSplitRouteV1(ic ingressContext, host string, prule *definitions.PathRuleV1) error {
+	s := ic.ipSplit
+	return ing.addCanaryRouteV1(ic, host, prule, "ipsplit", s.Service, s.backendPort(), s.predicate(ing.reverseSourcePredicate))
+}
@@ -1,18 +1,24 @@
 package kubernetes
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/loadbalancer"
 	"github.com/zalando/skipper/secrets/certregistry"
 )
 
@@ -127,6 +133,11 @@ type Options struct {
 	//		https://github.com/nginxinc/kubernetes-ingress/tree/master/examples/multiple-ingress-controllers
 	IngressClass string
 
+	// IngressClassAnnotationPrecedence, when set, makes the kubernetes.io/ingress.class annotation
+	// take precedence over spec.ingressClassName, when both are set and they differ. By default,
+	// the class from the spec wins. Conflicts are logged in both cases.
+	IngressClassAnnotationPrecedence bool
+
 	// RouteGroupClass is a regular expression to filter only those RouteGroups that match. If a RouteGroup
 	// does not have the required annotation (zalando.org/routegroup.class) or the annotation is an empty string,
 	// skipper will load it. The default value for the RouteGroup class is 'skipper'.
@@ -142,6 +153,12 @@ type Options struct {
 	// Noop, WIP.
 	ForceFullUpdatePeriod time.Duration
 
+	// DeleteGracePeriod, when set, delays reporting the deletion of a route by LoadUpdate,
+	// until the route is absent for at least this period. Routes that reappear within the
+	// grace period are never reported as deleted. This prevents flapping during rapid
+	// ingress churn.
+	DeleteGracePeriod time.Duration
+
 	// WhitelistedHealthcheckCIDR to be appended to the default iprange
 	WhitelistedHealthCheckCIDR []string
 
@@ -149,10 +166,136 @@ type Options struct {
 	// specify it with an annotation.
 	PathMode PathMode
 
+	// HostMatchPort controls how the port in the Host header is matched by the routes generated for
+	// the ingress, RouteGroup and east-west hosts. With "any", the default, any port or no port is accepted.
+	// With "none", the Host header must not contain a port. When set to a port number, e.g. "8443",
+	// only that port or no port is accepted.
+	HostMatchPort string
+
+	// HostTrailingDot controls how the hosts with a trailing dot, e.g. "www.example.org.", are matched
+	// by the routes generated for the ingress, RouteGroup and east-west hosts. Defaults to
+	// HostTrailingDotAllow.
+	HostTrailingDot HostTrailingDot
+
+	// StrictAnnotationParsing, when set, causes an ingress to be skipped when its filter annotations
+	// cannot be parsed. By default, the invalid filters are ignored, and the routes of the ingress are
+	// created without them.
+	StrictAnnotationParsing bool
+
+	// NodeCapacityLabel, when set, enables weighting the endpoints of the generated routes by the
+	// capacity of the nodes that they are running on. The node label with this name is expected to
+	// hold a positive integer, the relative weight of the endpoints on the node, up to 64. Endpoints
+	// on nodes without a valid label get weight 1. The weights are applied by repeating the
+	// endpoints in the route backends, which requires the roundRobin or random load balancer
+	// algorithms to take effect. Listing the nodes requires a cluster wide permission.
+	NodeCapacityLabel string
+
+	// RejectDuplicatePaths, when set, causes an ingress to be skipped when one of its rules contains
+	// the same path, with the same path type and backend service, more than once. By default, the
+	// last one of the duplicate paths is used.
+	RejectDuplicatePaths bool
+
+	// StartupNotReadyBehavior controls the routes created for the ingress backends, whose service
+	// has only not ready endpoints, e.g. a freshly created service. Defaults to NotReadyShunt.
+	StartupNotReadyBehavior NotReadyBehavior
+
+	// ShardCount, when greater than 1, enables partitioning the ingresses between multiple skipper
+	// instances. Each instance creates routes only for those ingresses, whose namespace and name
+	// hash to its ShardIndex, modulo ShardCount. RouteGroups are not partitioned.
+	ShardCount int
+
+	// ShardIndex is the index of the shard owned by this instance, between 0 and ShardCount-1.
+	ShardIndex int
+
+	// BackendWeightPrecision, when greater than 0, rounds the computed traffic weights of the
+	// ingress backends to the given number of decimal places. The last backend of a path keeps
+	// the weight of 1.0.
+	BackendWeightPrecision int
+
+	// AutoHandleOptions, when set, generates a route for each path of the ingress hosts, that
+	// responds to the OPTIONS requests, e.g. the CORS preflight requests, with 204 No Content,
+	// without forwarding them to the backends.
+	AutoHandleOptions bool
+
+	// AutoHandleOptionsHeaders are the response headers of the routes generated by
+	// AutoHandleOptions. When not set, the routes allow any origin, the common methods and any
+	// request headers.
+	AutoHandleOptionsHeaders map[string]string
+
+	// DefaultBackendConflictPolicy controls the routes of the default backends, when more than one
+	// ingress defines a default backend. By default, the routes of all the default backends are
+	// created, and the one used by the routing is undefined.
+	DefaultBackendConflictPolicy DefaultBackendConflictPolicy
+
+	// RouteIDHashSuffix, when set, appends a short hash of the route content to the IDs of the
+	// routes created for the ingresses and RouteGroups, e.g. kube_foo__bar__www_example_org_____baz_1a2b3c4d.
+	// The suffix is stable for identical routes, and it avoids the collisions of the route IDs,
+	// when different versions of the routes are present in a shared registry, e.g. during a
+	// migration of the route ID format.
+	RouteIDHashSuffix bool
+
+	// DefaultLoadBalancerAlgorithm is the load balancer algorithm of the routes with multiple
+	// endpoints, when the ingress or the RouteGroup backend doesn't set one. It must be one of the
+	// algorithms supported by the loadbalancer package. Defaults to roundRobin.
+	DefaultLoadBalancerAlgorithm string
+
+	// NormalizePaths, when set, prepends "/" to the ingress paths without a leading slash, e.g.
+	// "foo", which would never match the request paths, and logs a warning. It doesn't apply to
+	// the paths used as regular expressions in the PathRegexp path mode.
+	NormalizePaths bool
+
+	// EndpointLatencyProvider, when set, provides the measured latency of the endpoints, used to
+	// order and weight the endpoints of the load balanced routes, preferring the lower latency
+	// endpoints. Like the node capacity weights, the weights are applied by repeating the
+	// endpoints, which requires the roundRobin or random load balancer algorithms to take effect.
+	EndpointLatencyProvider EndpointLatencyProvider
+
+	// DefaultSecurityHeaders, when set, prepends filters to the routes of the ingresses, setting
+	// the Strict-Transport-Security, X-Content-Type-Options and X-Frame-Options response headers.
+	// An ingress can opt out with the zalando.org/skipper-security-headers annotation set to
+	// "false".
+	DefaultSecurityHeaders bool
+
+	// InspectionFilter is the eskip filter chain, e.g. a request inspection or WAF filter, added to
+	// the routes of the ingresses having the zalando.org/skipper-inspect annotation set to "true".
+	InspectionFilter string
+
+	// BackendHeaderName, when set, appends the backendEndpointHeader filter to the load balanced
+	// routes, setting the response header with this name to the address of the endpoint that
+	// served the request, e.g. for debugging the load balancing.
+	BackendHeaderName string
+
+	// GlobalPathPrefix, when set, is prepended to the paths of all the routes generated from
+	// the ingresses, and stripped from the request path by a modPath filter before proxying,
+	// e.g. for mounting the ingresses under a sub-path like /ingress.
+	GlobalPathPrefix string
+
+	// EndpointSubsetting, when the subset size is set, limits the endpoints of the load balanced
+	// routes to a stable subset selected for this replica. See EndpointSubsetting.
+	EndpointSubsetting EndpointSubsetting
+
+	// LenientListParsing, when set, skips the malformed items of the ingress list, logging them
+	// as errors, and converts the rest of the ingresses. By default, a single malformed item
+	// fails the whole load.
+	LenientListParsing bool
+
+	// OnLoadSummary, when set, is called at the end of each LoadAll and LoadUpdate with the
+	// summary of the load.
+	OnLoadSummary func(LoadSummary)
+
 	// *DEPRECATED *KubernetesEastWestDomain sets the DNS domain to be
 	// used for east west traffic, defaults to "skipper.cluster.local"
 	KubernetesEastWestDomain string
 
+	// EastWestHostOrder controls the order of the name and the namespace in the generated
+	// east-west hosts. Defaults to EastWestNameFirst.
+	EastWestHostOrder EastWestHostOrder
+
+	// LowercaseEastWestHosts, when set, lowercases the names and the namespaces in the generated
+	// east-west hosts, e.g. for matching the lowercased Host header of the requests when the
+	// proxy normalizes the hosts.
+	LowercaseEastWestHosts bool
+
 	// KubernetesEastWestRangeDomains set the the cluster internal domains for
 	// east west traffic. Identified routes to such domains will include
 	// the KubernetesEastWestRangePredicates.
@@ -182,7 +325,16 @@ type Options struct {
 	// used with external name services (type=ExternalName).
 	AllowedExternalNames []*regexp.Regexp
 
+	// AllowLocalExternalNames allows the external name services pointing to loopback, link-local
+	// or cloud metadata addresses, e.g. localhost, 127.0.0.1 or 169.254.169.254. By default they
+	// are rejected, because they could expose the internals of skipper or of the node.
+	AllowLocalExternalNames bool
+
 	CertificateRegistry *certregistry.CertRegistry
+
+	// TLSConflictPolicy controls the certificate registered for a host, when more than one ingress
+	// declares TLS for the host with different secrets. By default, the newest certificate is used.
+	TLSConflictPolicy TLSConflictPolicy
 }
 
 // Client is a Skipper DataClient implementation used to create routes based on Kubernetes Ingress settings.
@@ -197,8 +349,24 @@ type Client struct {
 	current                map[string]*eskip.Route
 	quit                   chan struct{}
 	defaultFiltersDir      string
+	deleteGracePeriod      time.Duration
+	pendingDeletes         map[string]time.Time
+	onLoadSummary          func(LoadSummary)
+	hostTrailingDot        HostTrailingDot
+	routeIDHashSuffix      bool
+	backendHeaderName      string
+	globalPathPrefix       string
+	endpointSubsetting     EndpointSubsetting
+
+	mu            sync.Mutex
+	ingressRoutes map[definitions.ResourceID][]*eskip.Route
+	loadedRoutes  []*eskip.Route
 }
 
+// errRoutesNotLoaded is returned by ExportRoutes, when the routes were not
+// loaded yet.
+var errRoutesNotLoaded = errors.New("routes not loaded")
+
 // New creates and initializes a Kubernetes DataClient.
 func New(o Options) (*Client, error) {
 	if !o.KubernetesIngressV1 {
@@ -250,6 +418,32 @@ func New(o Options) (*Client, error) {
 		}
 	}
 
+	if _, err := hostPortRx(o.HostMatchPort, o.HostTrailingDot); err != nil {
+		return nil, err
+	}
+
+	if o.DefaultLoadBalancerAlgorithm == "" {
+		o.DefaultLoadBalancerAlgorithm = defaultLoadBalancerAlgorithm
+	} else if a, err := loadbalancer.AlgorithmFromString(o.DefaultLoadBalancerAlgorithm); err != nil || a == loadbalancer.None {
+		return nil, fmt.Errorf("invalid default load balancer algorithm: %s", o.DefaultLoadBalancerAlgorithm)
+	}
+
+	if _, err := eskip.ParseFilters(o.InspectionFilter); err != nil {
+		return nil, fmt.Errorf("invalid inspection filter: %w", err)
+	}
+
+	if o.ShardCount > 1 && (o.ShardIndex < 0 || o.ShardIndex >= o.ShardCount) {
+		return nil, fmt.Errorf("invalid shard index: %d, expected between 0 and %d", o.ShardIndex, o.ShardCount-1)
+	}
+
+	if o.EndpointSubsetting.SubsetSize < 0 {
+		return nil, fmt.Errorf("invalid endpoint subset size: %d", o.EndpointSubsetting.SubsetSize)
+	}
+
+	if o.EndpointSubsetting.SubsetSize > 0 && o.EndpointSubsetting.ReplicaID == "" {
+		return nil, errors.New("endpoint subsetting requires a replica ID")
+	}
+
 	clusterClient, err := newClusterClient(o, apiURL, ingCls, rgCls, quit)
 	if err != nil {
 		return nil, err
@@ -273,6 +467,14 @@ func New(o Options) (*Client, error) {
 		reverseSourcePredicate: o.ReverseSourcePredicate,
 		quit:                   quit,
 		defaultFiltersDir:      o.DefaultFiltersDir,
+		deleteGracePeriod:      o.DeleteGracePeriod,
+		pendingDeletes:         make(map[string]time.Time),
+		onLoadSummary:          o.OnLoadSummary,
+		hostTrailingDot:        o.HostTrailingDot,
+		routeIDHashSuffix:      o.RouteIDHashSuffix,
+		backendHeaderName:      o.BackendHeaderName,
+		globalPathPrefix:       strings.TrimRight(o.GlobalPathPrefix, "/"),
+		endpointSubsetting:     o.EndpointSubsetting,
 	}, nil
 }
 
@@ -292,6 +494,161 @@ func buildAPIURL(o Options) (string, error) {
 	return "https://" + net.JoinHostPort(host, port), nil
 }
 
+// NotReadyBehavior values control the routes of the services, whose endpoints are
+// all not ready.
+type NotReadyBehavior int
+
+const (
+	// NotReadyShunt creates shunt routes for the services whose endpoints are all
+	// not ready, responding with 502. This is the default.
+	NotReadyShunt NotReadyBehavior = iota
+
+	// NotReadyRouteAnyway creates routes to the not ready endpoints, when a service
+	// has no ready endpoints.
+	NotReadyRouteAnyway
+
+	// NotReadyDrop doesn't create routes for the services whose endpoints are all
+	// not ready.
+	NotReadyDrop
+)
+
+// DefaultBackendConflictPolicy values control the routes of the default backends
+// of the ingresses, when more than one ingress defines a default backend.
+type DefaultBackendConflictPolicy int
+
+const (
+	// DefaultBackendConflictIgnore creates the routes of all the default backends,
+	// and the one used by the routing is undefined. This is the default.
+	DefaultBackendConflictIgnore DefaultBackendConflictPolicy = iota
+
+	// DefaultBackendConflictFirstWins uses only the default backend of the ingress
+	// that comes first, sorted by namespace and name.
+	DefaultBackendConflictFirstWins
+
+	// DefaultBackendConflictError logs an error and drops all the default backends,
+	// when more than one ingress defines one.
+	DefaultBackendConflictError
+
+	// DefaultBackendConflictPerHost uses the default backend of an ingress only for
+	// the hosts of its rules, as the route matching the paths not matched by the
+	// rules. The conflicts of the ingresses without rules are resolved as with
+	// DefaultBackendConflictFirstWins.
+	DefaultBackendConflictPerHost
+)
+
+// TLSConflictPolicy values control the certificate registered for a host, when
+// more than one ingress declares TLS for the host with different secrets.
+type TLSConflictPolicy int
+
+const (
+	// TLSConflictNewest registers the certificates of all the secrets, and the
+	// certificate registry keeps the one that became valid last. This is the
+	// default.
+	TLSConflictNewest TLSConflictPolicy = iota
+
+	// TLSConflictFirstWins registers only the certificate of the ingress that
+	// comes first, sorted by namespace and name.
+	TLSConflictFirstWins
+
+	// TLSConflictError logs an error and doesn't register any of the conflicting
+	// certificates of the host.
+	TLSConflictError
+)
+
+// HostTrailingDot values control how the trailing dot of the hosts is handled by
+// the routes generated for the ingresses and RouteGroups.
+type HostTrailingDot int
+
+const (
+	// HostTrailingDotAllow matches the hosts with or without a trailing dot. This
+	// is the default.
+	HostTrailingDotAllow HostTrailingDot = iota
+
+	// HostTrailingDotReject matches only the hosts without a trailing dot.
+	HostTrailingDotReject
+
+	// HostTrailingDotNormalize matches the hosts with or without a trailing dot,
+	// and removes the trailing dot from the Host header, using the rfcHost filter.
+	HostTrailingDotNormalize
+)
+
+// LoadSummary describes the result of a single load of the routes.
+type LoadSummary struct {
+	// Routes is the number of routes created from the cluster state, including the
+	// host catch-all, healthcheck and redirect routes.
+	Routes int
+
+	// ShuntedRoutes is the number of the created routes with a shunt backend, e.g.
+	// the catch-all routes and the routes of the services without endpoints.
+	ShuntedRoutes int
+
+	// Ingresses is the number of ingresses processed by this instance.
+	Ingresses int
+
+	// SkippedIngresses is the number of the processed ingresses that didn't result
+	// in any route, e.g. because of invalid annotations or missing services.
+	SkippedIngresses int
+
+	// Error is set when the load failed. In this case the other fields are zero.
+	Error error
+}
+
+// EastWestHostOrder values control the order of the name and the namespace in the
+// east-west hosts generated for the ingresses and RouteGroups.
+type EastWestHostOrder int
+
+const (
+	// EastWestNameFirst generates east-west hosts like name.namespace.domain.
+	// This is the default.
+	EastWestNameFirst EastWestHostOrder = iota
+
+	// EastWestNamespaceFirst generates east-west hosts like namespace.name.domain.
+	EastWestNamespaceFirst
+)
+
+func (o EastWestHostOrder) host(name, namespace, domain string) string {
+	if o == EastWestNamespaceFirst {
+		return namespace + "." + name + "." + domain
+	}
+
+	return name + "." + namespace + "." + domain
+}
+
+// eastWestHosts generates the east-west hosts of the ingresses and RouteGroups.
+type eastWestHosts struct {
+	order EastWestHostOrder
+
+	// lowercase is set with the LowercaseEastWestHosts option
+	lowercase bool
+
+	// portRx matches the trailing dot and the port of the east-west hosts,
+	// the same way as of the ingress and RouteGroup hosts
+	portRx string
+}
+
+func newEastWestHosts(o Options) eastWestHosts {
+	// the options are validated when creating the client
+	portRx, _ := hostPortRx(o.HostMatchPort, o.HostTrailingDot)
+	return eastWestHosts{
+		order:     o.EastWestHostOrder,
+		lowercase: o.LowercaseEastWestHosts,
+		portRx:    portRx,
+	}
+}
+
+func (h eastWestHosts) host(name, namespace, domain string) string {
+	host := h.order.host(name, namespace, domain)
+	if h.lowercase {
+		return strings.ToLower(host)
+	}
+
+	return host
+}
+
+func (h eastWestHosts) hostRx(name, namespace, domain string) string {
+	return createHostRxPort(h.portRx, h.host(name, namespace, domain))
+}
+
 // String returns the string representation of the path mode, the same
 // values that are used in the path mode annotation.
 func (m PathMode) String() string {
@@ -347,8 +704,28 @@ func (c *Client) loadAndConvert() ([]*eskip.Route, error) {
 		return nil, err
 	}
 
+	if c.globalPathPrefix != "" {
+		applyGlobalPathPrefix(ri, c.globalPathPrefix)
+	}
+
 	r := append(ri, rg...)
 
+	if c.hostTrailingDot == HostTrailingDotNormalize {
+		normalizeHosts(r)
+	}
+
+	if c.endpointSubsetting.SubsetSize > 0 {
+		subsetEndpoints(r, c.endpointSubsetting)
+	}
+
+	if c.backendHeaderName != "" {
+		appendBackendHeader(r, c.backendHeaderName)
+	}
+
+	if c.routeIDHashSuffix {
+		c.ingress.routeOwners = appendRouteIDHashes(r, c.ingress.routeOwners)
+	}
+
 	if c.provideHealthcheck {
 		r = append(r, healthcheckRoutes(c.reverseSourcePredicate)...)
 	}
@@ -360,6 +737,105 @@ func (c *Client) loadAndConvert() ([]*eskip.Route, error) {
 	return r, nil
 }
 
+// normalizeHosts prepends the rfcHost filter to the routes matching the hosts,
+// removing the trailing dot from the Host header.
+func normalizeHosts(r []*eskip.Route) {
+	for _, ri := range r {
+		if len(ri.HostRegexps) == 0 {
+			continue
+		}
+
+		ri.Filters = append([]*eskip.Filter{{Name: filters.RfcHostName}}, ri.Filters...)
+	}
+}
+
+// appendBackendHeader appends the backendEndpointHeader filter to the load
+// balanced routes, exposing the endpoint selected for the request.
+func appendBackendHeader(r []*eskip.Route, name string) {
+	for _, ri := range r {
+		if ri.BackendType != eskip.LBBackend {
+			continue
+		}
+
+		ri.Filters = append(ri.Filters, &eskip.Filter{Name: filters.BackendEndpointHeaderName, Args: []interface{}{name}})
+	}
+}
+
+// applyGlobalPathPrefix prepends the prefix to the path conditions of the
+// routes, and strips it from the request path before proxying. Routes without
+// path conditions get matched by the whole subtree of the prefix.
+func applyGlobalPathPrefix(r []*eskip.Route, prefix string) {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	rxPrefix := "^" + regexp.QuoteMeta(prefix)
+	for _, ri := range r {
+		hasPath := ri.Path != "" || len(ri.PathRegexps) > 0
+		if ri.Path != "" {
+			ri.Path = prefix + ri.Path
+		}
+
+		for i, rx := range ri.PathRegexps {
+			if strings.HasPrefix(rx, "^") {
+				ri.PathRegexps[i] = rxPrefix + rx[1:]
+			} else {
+				ri.PathRegexps[i] = rxPrefix + ".*" + rx
+			}
+		}
+
+		for _, p := range ri.Predicates {
+			if p.Name != "Path" && p.Name != "PathSubtree" || len(p.Args) != 1 {
+				continue
+			}
+
+			path, ok := p.Args[0].(string)
+			if !ok {
+				continue
+			}
+
+			hasPath = true
+			if p.Name == "PathSubtree" && path == "/" {
+				p.Args[0] = prefix
+			} else {
+				p.Args[0] = prefix + path
+			}
+		}
+
+		if !hasPath {
+			ri.Predicates = append(ri.Predicates, &eskip.Predicate{Name: "PathSubtree", Args: []interface{}{prefix}})
+		}
+
+		ri.Filters = append([]*eskip.Filter{{
+			Name: filters.ModPathName,
+			Args: []interface{}{rxPrefix + "(/|$)", "/"},
+		}}, ri.Filters...)
+	}
+}
+
+// routeContentHash returns a short hash of the route, excluding its ID.
+func routeContentHash(r *eskip.Route) string {
+	h := fnv.New32a()
+	h.Write([]byte(r.String()))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// appendRouteIDHashes appends the content hash to the IDs of the routes, and
+// returns the route owners mapped to the new IDs.
+func appendRouteIDHashes(r []*eskip.Route, owners map[string]definitions.ResourceID) map[string]definitions.ResourceID {
+	hashedOwners := make(map[string]definitions.ResourceID, len(owners))
+	for _, ri := range r {
+		id := ri.Id + "_" + routeContentHash(ri)
+		if owner, ok := owners[ri.Id]; ok {
+			hashedOwners[id] = owner
+		}
+
+		ri.Id = id
+	}
+
+	return hashedOwners
+}
+
 func shuntRoute(r *eskip.Route) {
 	r.Filters = []*eskip.Filter{
 		{
@@ -387,11 +863,7 @@ func healthcheckRoutes(reverseSourcePredicate bool) []*eskip.Route {
 		DisableAccessLog string
 	}{}
 
-	if reverseSourcePredicate {
-		params.Source = "SourceFromLast"
-	} else {
-		params.Source = "Source"
-	}
+	params.Source = sourcePredicateName(reverseSourcePredicate)
 
 	if !log.IsLevelEnabled(log.DebugLevel) {
 		params.DisableAccessLog = "disableAccessLog(200) ->"
@@ -416,11 +888,14 @@ func healthcheckRoutes(reverseSourcePredicate bool) []*eskip.Route {
 func (c *Client) LoadAll() ([]*eskip.Route, error) {
 	log.Debug("loading all")
 	r, err := c.loadAndConvert()
+	c.reportLoadSummary(r, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load cluster state: %w", err)
 	}
 
 	c.current = mapRoutes(r)
+	c.pendingDeletes = make(map[string]time.Time)
+	c.mapIngressRoutes()
 	log.Debugf("all routes loaded and mapped")
 
 	return r, nil
@@ -433,6 +908,7 @@ func (c *Client) LoadAll() ([]*eskip.Route, error) {
 func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 	log.Debugf("polling for updates")
 	r, err := c.loadAndConvert()
+	c.reportLoadSummary(r, err)
 	if err != nil {
 		log.Errorf("polling for updates failed: %v", err)
 		return nil, nil, err
@@ -446,12 +922,25 @@ func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 		deletedIDs    []string
 	)
 
+	now := time.Now()
 	for id := range c.current {
-		// TODO: use eskip.Eq()
-		if r, ok := next[id]; ok && r.String() != c.current[id].String() {
-			updatedRoutes = append(updatedRoutes, r)
-		} else if !ok {
+		r, ok := next[id]
+		switch {
+		case ok:
+			delete(c.pendingDeletes, id)
+
+			// TODO: use eskip.Eq()
+			if r.String() != c.current[id].String() {
+				updatedRoutes = append(updatedRoutes, r)
+			}
+		case c.deleteGracePeriod <= 0:
+			deletedIDs = append(deletedIDs, id)
+		case c.deleteExpired(id, now):
+			delete(c.pendingDeletes, id)
 			deletedIDs = append(deletedIDs, id)
+		default:
+			// keep the route until the grace period expires
+			next[id] = c.current[id]
 		}
 	}
 
@@ -466,9 +955,99 @@ func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 	}
 
 	c.current = next
+	c.mapIngressRoutes()
 	return updatedRoutes, deletedIDs, nil
 }
 
+// reportLoadSummary calls the OnLoadSummary callback, if set, with the
+// summary of the routes created by the last conversion.
+func (c *Client) reportLoadSummary(r []*eskip.Route, err error) {
+	if c.onLoadSummary == nil {
+		return
+	}
+
+	if err != nil {
+		c.onLoadSummary(LoadSummary{Error: err})
+		return
+	}
+
+	s := LoadSummary{Routes: len(r), Ingresses: c.ingress.processed}
+	for _, ri := range r {
+		if ri.BackendType == eskip.ShuntBackend {
+			s.ShuntedRoutes++
+		}
+	}
+
+	owners := make(map[definitions.ResourceID]bool)
+	for _, owner := range c.ingress.routeOwners {
+		owners[owner] = true
+	}
+
+	s.SkippedIngresses = s.Ingresses - len(owners)
+	c.onLoadSummary(s)
+}
+
+// mapIngressRoutes groups the current routes by the ingress they were
+// created for, and keeps them sorted by route ID for the export.
+func (c *Client) mapIngressRoutes() {
+	m := make(map[definitions.ResourceID][]*eskip.Route)
+	all := make([]*eskip.Route, 0, len(c.current))
+	for id, r := range c.current {
+		all = append(all, r)
+		if owner, ok := c.ingress.routeOwners[id]; ok {
+			m[owner] = append(m[owner], r)
+		}
+	}
+
+	for _, routes := range m {
+		sort.Slice(routes, func(i, j int) bool { return routes[i].Id < routes[j].Id })
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Id < all[j].Id })
+
+	c.mu.Lock()
+	c.ingressRoutes = m
+	c.loadedRoutes = all
+	c.mu.Unlock()
+}
+
+// RoutesForIngress returns the routes created for an ingress during the
+// last load, sorted by route ID. Routes shared by multiple ingresses, e.g.
+// the host catch-all routes, are not returned. It is safe to call it
+// concurrently with the loading of the routes.
+func (c *Client) RoutesForIngress(namespace, name string) []*eskip.Route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return eskip.CopyRoutes(c.ingressRoutes[newResourceID(namespace, name)])
+}
+
+// ExportRoutes returns the routes of the last load in eskip format, sorted by
+// route ID, e.g. to store them for audits. The routes kept during the delete
+// grace period are included. It is safe to call it concurrently with the
+// loading of the routes.
+func (c *Client) ExportRoutes() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loadedRoutes == nil {
+		return nil, errRoutesNotLoaded
+	}
+
+	return []byte(eskip.Print(eskip.PrettyPrintInfo{Pretty: true, IndentStr: "  "}, c.loadedRoutes...)), nil
+}
+
+// deleteExpired marks the route as pending for deletion, when seen missing the
+// first time, and tells whether it has been missing for longer than the grace
+// period.
+func (c *Client) deleteExpired(id string, now time.Time) bool {
+	since, ok := c.pendingDeletes[id]
+	if !ok {
+		c.pendingDeletes[id] = now
+		return false
+	}
+
+	return now.Sub(since) >= c.deleteGracePeriod
+}
+
 func (c *Client) Close() {
 	if c != nil && c.quit != nil {
 		close(c.quit)
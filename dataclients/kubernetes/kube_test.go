@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
@@ -15,11 +16,13 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
 	"testing/quick"
 	"time"
@@ -31,17 +34,23 @@ import (
 
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/predicates/source"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
 	"github.com/zalando/skipper/secrets/certregistry"
 )
 
 type testAPI struct {
-	test      *testing.T
-	services  *serviceList
-	endpoints *endpointList
-	ingresses *definitions.IngressList
-	secrets   *secretList
-	server    *httptest.Server
-	failNext  bool
+	test        *testing.T
+	services    *serviceList
+	endpoints   *endpointList
+	ingresses   *definitions.IngressList
+	ingressesV1 *definitions.IngressV1List
+	secrets     *secretList
+	server      *httptest.Server
+	failNext    bool
 }
 
 func init() {
@@ -397,6 +406,11 @@ func (api *testAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			api.test.Error(err)
 		}
 		return
+	case IngressesV1ClusterURI:
+		if err := respondJSON(w, api.ingressesV1); err != nil {
+			api.test.Error(err)
+		}
+		return
 	case ServicesClusterURI:
 		if err := respondJSON(w, api.services); err != nil {
 			api.test.Error(err)
@@ -578,6 +592,354 @@ func TestIngressClassFilter(t *testing.T) {
 	}
 }
 
+func TestIngressV1ClassPrecedence(t *testing.T) {
+	for _, test := range []struct {
+		title                string
+		annotationClass      string
+		specClass            string
+		annotationPrecedence bool
+		expectLoaded         bool
+	}{{
+		title:           "spec wins by default",
+		annotationClass: "other",
+		specClass:       "skipper",
+		expectLoaded:    true,
+	}, {
+		title:           "spec wins by default, not matching",
+		annotationClass: "skipper",
+		specClass:       "other",
+	}, {
+		title:                "annotation wins when configured",
+		annotationClass:      "skipper",
+		specClass:            "other",
+		annotationPrecedence: true,
+		expectLoaded:         true,
+	}, {
+		title:                "annotation wins when configured, not matching",
+		annotationClass:      "other",
+		specClass:            "skipper",
+		annotationPrecedence: true,
+	}, {
+		title:           "only annotation",
+		annotationClass: "skipper",
+		expectLoaded:    true,
+	}, {
+		title:     "only spec, not matching",
+		specClass: "other",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			c := &clusterClient{
+				ingressClass:                     regexp.MustCompile("^skipper$"),
+				ingressClassAnnotationPrecedence: test.annotationPrecedence,
+			}
+
+			ing := &definitions.IngressV1Item{
+				Metadata: &definitions.Metadata{
+					Namespace:   "foo",
+					Name:        "bar",
+					Annotations: map[string]string{ingressClassKey: test.annotationClass},
+				},
+				Spec: &definitions.IngressV1Spec{IngressClassName: test.specClass},
+			}
+
+			result := c.filterIngressesV1ByClass([]*definitions.IngressV1Item{ing})
+			if test.expectLoaded && len(result) != 1 {
+				t.Error("expected the ingress to be loaded")
+			} else if !test.expectLoaded && len(result) != 0 {
+				t.Error("expected the ingress to be filtered out")
+			}
+		})
+	}
+}
+
+func TestRoutesForIngress(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{Items: testIngresses()}, testEndpointList(), testSecrets())
+	defer api.Close()
+
+	dc, err := New(Options{KubernetesURL: api.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	if _, err := dc.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, dc.RoutesForIngress("namespace1", "mega"), map[string]string{
+		"kube_namespace1__mega______":                              "http://1.1.1.0:8080",
+		"kube_namespace1__mega__foo_example_org___test1__service1": "http://1.1.1.0:8080",
+		"kube_namespace1__mega__foo_example_org___test2__service2": "http://1.1.2.0:8181",
+		"kube_namespace1__mega__bar_example_org___test1__service1": "http://1.1.1.0:8080",
+		"kube_namespace1__mega__bar_example_org___test2__service2": "http://1.1.2.0:8181",
+	})
+
+	if r := dc.RoutesForIngress("namespace1", "not-existing"); len(r) != 0 {
+		t.Errorf("unexpected routes for not existing ingress: %v", r)
+	}
+}
+
+func TestExportRoutes(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{Items: testIngresses()}, testEndpointList(), testSecrets())
+	defer api.Close()
+
+	dc, err := New(Options{KubernetesURL: api.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	if _, err := dc.ExportRoutes(); err == nil {
+		t.Error("expected error before loading the routes")
+	}
+
+	routes, err := dc.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := dc.ExportRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := dc.ExportRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(export) != string(again) {
+		t.Error("export is not deterministic")
+	}
+
+	parsed, err := eskip.Parse(string(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Id < routes[j].Id })
+	if !eskip.EqLists(parsed, routes) {
+		t.Errorf("exported routes do not round-trip, got:\n%s", export)
+	}
+}
+
+func TestRouteIDHashSuffix(t *testing.T) {
+	ingresses := &definitions.IngressList{Items: []*definitions.IngressItem{
+		testIngress("namespace1", "mega", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("foo.example.org", testPathRule("/test1", "service1", definitions.BackendPort{Value: 8080})),
+			testRule("bar.example.org", testPathRule("/test2", "service2", definitions.BackendPort{Value: "port2"})),
+		),
+	}}
+
+	load := func(t *testing.T, ingresses *definitions.IngressList, hashSuffix bool) []*eskip.Route {
+		api := newTestAPIWithEndpoints(t, testServices(), ingresses, testEndpointList(), testSecrets())
+		defer api.Close()
+
+		dc, err := New(Options{KubernetesURL: api.server.URL, RouteIDHashSuffix: hashSuffix})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer dc.Close()
+
+		r, err := dc.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hashSuffix {
+			// the routes stay unchanged, when the cluster state doesn't change
+			update, deleted, err := dc.LoadUpdate()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(update) != 0 || len(deleted) != 0 {
+				t.Errorf("unexpected update: %d, %d", len(update), len(deleted))
+			}
+		}
+
+		return r
+	}
+
+	plain := load(t, ingresses, false)
+	hashed := load(t, ingresses, true)
+	if len(plain) != len(hashed) {
+		t.Fatalf("expected %d routes, got: %d", len(plain), len(hashed))
+	}
+
+	routeIDs := func(r []*eskip.Route) map[string]bool {
+		ids := make(map[string]bool)
+		for _, ri := range r {
+			ids[ri.Id] = true
+		}
+
+		return ids
+	}
+
+	plainIDs := routeIDs(plain)
+	suffixRx := regexp.MustCompile("^(.+)_[0-9a-f]{8}$")
+	for _, r := range hashed {
+		m := suffixRx.FindStringSubmatch(r.Id)
+		if len(m) != 2 || !plainIDs[m[1]] {
+			t.Errorf("expected a route ID with a hash suffix, got: %s", r.Id)
+		}
+	}
+
+	hashedIDs := routeIDs(hashed)
+	for id := range routeIDs(load(t, ingresses, true)) {
+		if !hashedIDs[id] {
+			t.Errorf("unstable route ID: %s", id)
+		}
+	}
+
+	// the suffix changes when the content of the route changes
+	ingresses.Items[0].Spec.Rules[0].Http.Paths[0].Path = "/test3"
+	var changedIDs int
+	for id := range routeIDs(load(t, ingresses, true)) {
+		if !hashedIDs[id] {
+			changedIDs++
+		}
+	}
+
+	if changedIDs == 0 {
+		t.Error("expected changed route IDs")
+	}
+}
+
+func TestLoadSummary(t *testing.T) {
+	services := testServices()
+	services.Items = append(services.Items, testService("namespace1", "service5", "1.2.3.5", map[string]int{"port5": 8080}))
+	ingresses := []*definitions.IngressItem{
+		testIngress("namespace1", "ok", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("foo.example.org", testPathRule("/test1", "service1", definitions.BackendPort{Value: 8080})),
+		),
+		testIngress("namespace1", "no-endpoints", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("bar.example.org", testPathRule("/test1", "service5", definitions.BackendPort{Value: 8080})),
+		),
+		testIngress("namespace1", "missing-service", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("baz.example.org", testPathRule("/test1", "missing", definitions.BackendPort{Value: 8080})),
+		),
+	}
+
+	api := newTestAPIWithEndpoints(t, services, &definitions.IngressList{Items: ingresses}, testEndpointList(), testSecrets())
+	defer api.Close()
+
+	var summaries []LoadSummary
+	dc, err := New(Options{
+		KubernetesURL: api.server.URL,
+		OnLoadSummary: func(s LoadSummary) { summaries = append(summaries, s) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	if _, err := dc.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := dc.LoadUpdate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the route of service1, the shunt route of service5 without endpoints, and
+	// the catch-all routes of their hosts, while the ingress of the missing
+	// service is skipped
+	expected := LoadSummary{
+		Routes:           4,
+		ShuntedRoutes:    3,
+		Ingresses:        3,
+		SkippedIngresses: 1,
+	}
+
+	if len(summaries) != 2 {
+		t.Fatalf("expected a summary for each load, got: %d", len(summaries))
+	}
+
+	for _, s := range summaries {
+		if s != expected {
+			t.Errorf("unexpected load summary: %+v, expected: %+v", s, expected)
+		}
+	}
+
+	api.failNext = true
+	summaries = nil
+	if _, _, err := dc.LoadUpdate(); err == nil {
+		t.Fatal("failed to fail")
+	}
+
+	if len(summaries) != 1 || summaries[0].Error == nil || summaries[0].Routes != 0 {
+		t.Errorf("unexpected load summary of a failed load: %+v", summaries)
+	}
+}
+
+func TestIngressSharding(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{Items: testIngresses()}, testEndpointList(), testSecrets())
+	defer api.Close()
+
+	load := func(o Options) *Client {
+		o.KubernetesURL = api.server.URL
+		dc, err := New(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := dc.LoadAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		return dc
+	}
+
+	all := load(Options{})
+	defer all.Close()
+
+	const shardCount = 3
+	var shards []*Client
+	for i := 0; i < shardCount; i++ {
+		dc := load(Options{ShardCount: shardCount, ShardIndex: i})
+		defer dc.Close()
+		shards = append(shards, dc)
+	}
+
+	for _, ing := range testIngresses() {
+		ns, name := ing.Metadata.Namespace, ing.Metadata.Name
+		expected := all.RoutesForIngress(ns, name)
+		if len(expected) == 0 {
+			t.Fatalf("no routes for %s/%s", ns, name)
+		}
+
+		var owners int
+		for i, dc := range shards {
+			routes := dc.RoutesForIngress(ns, name)
+			if len(routes) == 0 {
+				continue
+			}
+
+			owners++
+			if !reflect.DeepEqual(routes, expected) {
+				t.Errorf("unexpected routes for %s/%s in shard %d, got: %v, expected: %v", ns, name, i, routes, expected)
+			}
+		}
+
+		if owners != 1 {
+			t.Errorf("expected %s/%s to be owned by exactly one shard, got: %d", ns, name, owners)
+		}
+	}
+}
+
+func TestInvalidShardIndex(t *testing.T) {
+	for _, index := range []int{-1, 3} {
+		if _, err := New(Options{KubernetesURL: "http://localhost:8001", ShardCount: 3, ShardIndex: index}); err == nil {
+			t.Errorf("failed to fail for shard index: %d", index)
+		}
+	}
+}
+
 func TestIngress(t *testing.T) {
 	api := newTestAPI(t, nil, &definitions.IngressList{})
 	defer api.Close()
@@ -1308,6 +1670,70 @@ func TestConvertPathRuleEastWestEnabled(t *testing.T) {
 	})
 }
 
+func TestEastWestDuplicateRoutes(t *testing.T) {
+	ingress := func(name, port string) *definitions.IngressItem {
+		return testIngress("namespace2", name, "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("foo.example.org", testPathRule("/test1", "service4", definitions.BackendPort{Value: port})),
+		)
+	}
+
+	for _, test := range []struct {
+		title     string
+		ingresses []*definitions.IngressItem
+		id        string
+		host      string
+	}{{
+		// the route IDs are the same, because the names differ only in
+		// non-word characters
+		title:     "names differing in non-word characters",
+		ingresses: []*definitions.IngressItem{ingress("a.b", "port5"), ingress("a-b", "port4")},
+		id:        "kubeew_namespace2__a_b__foo_example_org___test1__service4",
+		host:      "^(a-b[.]namespace2[.]skipper[.]cluster[.]local[.]?(:[0-9]+)?)$",
+	}, {
+		title:     "same ingress listed twice",
+		ingresses: []*definitions.IngressItem{ingress("dup", "port5"), ingress("dup", "port4")},
+		id:        "kubeew_namespace2__dup__foo_example_org___test1__service4",
+		host:      "^(dup[.]namespace2[.]skipper[.]cluster[.]local[.]?(:[0-9]+)?)$",
+	}, {
+		title:     "same ingress listed twice, reverse order",
+		ingresses: []*definitions.IngressItem{ingress("dup", "port4"), ingress("dup", "port5")},
+		id:        "kubeew_namespace2__dup__foo_example_org___test1__service4",
+		host:      "^(dup[.]namespace2[.]skipper[.]cluster[.]local[.]?(:[0-9]+)?)$",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{Items: test.ingresses}, testEndpointList(), testSecrets())
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL, KubernetesEnableEastWest: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ew []*eskip.Route
+			for _, ri := range r {
+				if ri.Id == test.id {
+					ew = append(ew, ri)
+				}
+			}
+
+			if len(ew) != 1 {
+				t.Fatalf("expected a single east-west route, got: %d", len(ew))
+			}
+
+			if ew[0].Backend != "http://2.1.4.0:4444" || ew[0].HostRegexps[0] != test.host {
+				t.Errorf("unexpected east-west route: %v", ew[0])
+			}
+		})
+	}
+}
+
 func TestConvertPathRuleTraffic(t *testing.T) {
 	for _, tc := range []struct {
 		msg   string
@@ -1391,7 +1817,12 @@ func TestConvertPathRuleTraffic(t *testing.T) {
 				"",
 				tc.rule,
 				KubernetesIngressMode,
+				anyPortRx,
 				nil,
+				false,
+				defaultLoadBalancerAlgorithm,
+				nil,
+				newDiagnostics(log.NewEntry(log.StandardLogger())),
 			)
 			if err != nil {
 				t.Errorf("should not fail: %v", err)
@@ -2388,6 +2819,47 @@ func TestComputeBackendWeightMustHaveFallback(t *testing.T) {
 	}
 }
 
+func TestRoundBackendWeights(t *testing.T) {
+	for _, test := range []struct {
+		precision int
+		expected  []float64
+	}{{
+		precision: 2,
+		expected:  []float64{0.33, 0.5, 1.0},
+	}, {
+		precision: 4,
+		expected:  []float64{0.3333, 0.5, 1.0},
+	}} {
+		t.Run(fmt.Sprintf("precision %d", test.precision), func(t *testing.T) {
+			weights := map[string]float64{"foo": 1, "bar": 1, "baz": 1}
+			rule := &definitions.RuleV1{
+				Http: &definitions.HTTPRuleV1{
+					Paths: []*definitions.PathRuleV1{
+						{Path: "/", Backend: &definitions.BackendV1{Service: definitions.Service{Name: "foo"}}},
+						{Path: "/", Backend: &definitions.BackendV1{Service: definitions.Service{Name: "bar"}}},
+						{Path: "/", Backend: &definitions.BackendV1{Service: definitions.Service{Name: "baz"}}},
+					},
+				},
+			}
+
+			// rounding the same rule again must not change the weights
+			for i := 0; i < 2; i++ {
+				computeBackendWeightsV1(weights, rule)
+				roundBackendWeightsV1(rule, test.precision)
+				for j, path := range rule.Http.Paths {
+					if path.Backend.Traffic != test.expected[j] {
+						t.Errorf("unexpected weight of %s: %v, expected: %v", path.Backend.Service.Name, path.Backend.Traffic, test.expected[j])
+					}
+				}
+			}
+		})
+	}
+
+	if w := roundTraffic(0.001, 2); w != 0.01 {
+		t.Errorf("expected non-zero weight to stay non-zero, got: %v", w)
+	}
+}
+
 func TestRatelimits(t *testing.T) {
 	api := newTestAPI(t, nil, &definitions.IngressList{})
 	defer api.Close()
@@ -2412,7 +2884,8 @@ func TestRatelimits(t *testing.T) {
 		}
 
 		checkLocalRatelimit(t, r, map[string]string{
-			"kube_namespace1__ratelimit______": "localRatelimit(20,\"1m\")",
+			"kube_namespace1__ratelimit______":           "localRatelimit(20,\"1m\")",
+			"kube_namespace1__ratelimitAndBreaker______": "localRatelimit(20,\"1m\")",
 		})
 	})
 }
@@ -2442,25 +2915,31 @@ func TestRatelimitsEastWest(t *testing.T) {
 		}
 
 		checkLocalRatelimit(t, r, map[string]string{
-			"kube_namespace1__ratelimit______":   "localRatelimit(20,\"1m\")",
-			"kubeew_namespace1__ratelimit______": "localRatelimit(20,\"1m\")",
+			"kube_namespace1__ratelimit______":             "localRatelimit(20,\"1m\")",
+			"kubeew_namespace1__ratelimit______":           "localRatelimit(20,\"1m\")",
+			"kube_namespace1__ratelimitAndBreaker______":   "localRatelimit(20,\"1m\")",
+			"kubeew_namespace1__ratelimitAndBreaker______": "localRatelimit(20,\"1m\")",
 		})
 	})
 }
 
 func checkLocalRatelimit(t *testing.T, got []*eskip.Route, expected map[string]string) {
 	for _, r := range got {
-		if r.Filters != nil {
-			for _, f := range r.Filters {
-				_, ok := expected[r.Id]
-				if ok && f.Name != "localRatelimit" {
-					t.Errorf("%s should have a localratelimit", r.Id)
-				}
-				if !ok && f.Name == "localRatelimit" {
-					t.Errorf("%s should not have a localratelimit", r.Id)
-				}
+		var found bool
+		for _, f := range r.Filters {
+			if f.Name == "localRatelimit" {
+				found = true
+				break
 			}
 		}
+
+		_, ok := expected[r.Id]
+		if ok && !found {
+			t.Errorf("%s should have a localratelimit", r.Id)
+		}
+		if !ok && found {
+			t.Errorf("%s should not have a localratelimit", r.Id)
+		}
 	}
 }
 
@@ -2651,8 +3130,8 @@ func TestSkipperCustomRoutes(t *testing.T) {
 			testRule("www1.example.org", testPathRule("/a/path", "bar", definitions.BackendPort{Value: "baz"})),
 		)},
 		expectedRoutes: map[string]string{
-			"kube_foo__qux__www1_example_org___a_path__bar": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"http://1.1.1.0:8181\"",
-			"kube_foo__qux__0__www1_example_org_a_path____": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") -> <shunt>",
+			"kube_foo__qux__www1_example_org___a_path__bar": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux__0__www1_example_org_a_path____": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> <shunt>",
 			"kube___catchall__www1_example_org____":         "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
 		},
 	}, {
@@ -2686,11 +3165,11 @@ func TestSkipperCustomRoutes(t *testing.T) {
 			testRule("www2.example.org", testPathRule("/another/path", "bar", definitions.BackendPort{Value: "baz"})),
 		)},
 		expectedRoutes: map[string]string{
-			"kube_foo__qux__www1_example_org___a_path__bar":       "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"http://1.1.1.0:8181\"",
-			"kube_foo__qux__0__www1_example_org_a_path____":       "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") -> <shunt>",
+			"kube_foo__qux__www1_example_org___a_path__bar":       "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux__0__www1_example_org_a_path____":       "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> <shunt>",
 			"kube___catchall__www1_example_org____":               "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
-			"kube_foo__qux__www2_example_org___another_path__bar": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/another\\/path)/) -> \"http://1.1.1.0:8181\"",
-			"kube_foo__qux__0__www2_example_org_another_path____": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/another\\/path)/) && Method(\"OPTIONS\") -> <shunt>",
+			"kube_foo__qux__www2_example_org___another_path__bar": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/another\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux__0__www2_example_org_another_path____": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/another\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> <shunt>",
 			"kube___catchall__www2_example_org____":               "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
 		},
 	}, {
@@ -2721,10 +3200,10 @@ func TestSkipperCustomRoutes(t *testing.T) {
 			"kube_foo__qux_b_1__www2_example_org_____": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
 			"kube_foo__qux_c_2__www2_example_org_____": "Path(\"/a/path/somewhere\") && Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) -> \"https://some.other-url.org/a/path/somewhere\"",
 
-			"kube_foo__qux__www3_example_org___a_path__bar":  "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"http://1.1.1.0:8181\"",
-			"kube_foo__qux_a_0__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") -> <shunt>",
-			"kube_foo__qux_b_1__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
-			"kube_foo__qux_c_2__www3_example_org_a_path____": "Path(\"/a/path/somewhere\") && Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"https://some.other-url.org/a/path/somewhere\"",
+			"kube_foo__qux__www3_example_org___a_path__bar":  "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux_a_0__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> <shunt>",
+			"kube_foo__qux_b_1__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
+			"kube_foo__qux_c_2__www3_example_org_a_path____": "Path(\"/a/path/somewhere\") && Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"https://some.other-url.org/a/path/somewhere\"",
 			"kube___catchall__www3_example_org____":          "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
 		},
 	}, {
@@ -2755,10 +3234,10 @@ func TestSkipperCustomRoutes(t *testing.T) {
 			"kube_foo__qux_b_1__www2_example_org_____": "Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
 			"kube_foo__qux_c_2__www2_example_org_____": "Path(\"/a/path/somewhere\") && Host(/^(www2[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) -> \"https://some.other-url.org/a/path/somewhere\"",
 
-			"kube_foo__qux__www3_example_org___a_path__bar":  "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"http://1.1.1.0:8181\"",
-			"kube_foo__qux_a_0__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") -> <shunt>",
-			"kube_foo__qux_b_1__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
-			"kube_foo__qux_c_2__www3_example_org_a_path____": "Path(\"/a/path/somewhere\") && Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) -> \"https://some.other-url.org/a/path/somewhere\"",
+			"kube_foo__qux__www3_example_org___a_path__bar":  "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux_a_0__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> <shunt>",
+			"kube_foo__qux_b_1__www3_example_org_a_path____": "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) && Cookie(\"alpha\", \"^enabled$\") -> \"http://1.1.2.0:8181\"",
+			"kube_foo__qux_c_2__www3_example_org_a_path____": "Path(\"/a/path/somewhere\") && Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"https://some.other-url.org/a/path/somewhere\"",
 			"kube___catchall__www3_example_org____":          "Host(/^(www3[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
 		},
 	}, {
@@ -2816,28 +3295,169 @@ func TestSkipperCustomRoutes(t *testing.T) {
 	}
 }
 
-func checkPrettyRoutes(t *testing.T, r []*eskip.Route, expected map[string]string) {
-	if len(r) != len(expected) {
-		curIDs := make([]string, len(r))
-		expectedIDs := make([]string, len(expected))
-		for i := range r {
-			curIDs[i] = r[i].Id
-		}
-		j := 0
-		for k := range expected {
-			expectedIDs[j] = k
-			j++
-		}
+func TestAutoHandleOptions(t *testing.T) {
+	for _, ti := range []struct {
+		msg            string
+		enabled        bool
+		headers        map[string]string
+		rule           *definitions.Rule
+		expectedRoutes map[string]string
+	}{{
+		msg:  "disabled",
+		rule: testRule("www1.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+		expectedRoutes: map[string]string{
+			"kube_foo__qux__www1_example_org_____bar": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) -> \"http://1.1.1.0:8181\"",
+		},
+	}, {
+		msg:     "enabled, default headers",
+		enabled: true,
+		rule:    testRule("www1.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+		expectedRoutes: map[string]string{
+			"kube_foo__qux__www1_example_org_____bar":         "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux__www1_example_org_____bar_options": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^\\//) && Method(\"OPTIONS\") -> setResponseHeader(\"Access-Control-Allow-Headers\", \"*\") -> setResponseHeader(\"Access-Control-Allow-Methods\", \"GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS\") -> setResponseHeader(\"Access-Control-Allow-Origin\", \"*\") -> status(204) -> <shunt>",
+		},
+	}, {
+		msg:     "enabled, custom headers",
+		enabled: true,
+		headers: map[string]string{"Access-Control-Allow-Origin": "https://app.example.org"},
+		rule:    testRule("www1.example.org", testPathRule("/a/path", "bar", definitions.BackendPort{Value: "baz"})),
+		expectedRoutes: map[string]string{
+			"kube_foo__qux__www1_example_org___a_path__bar":         "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Weight(1) -> \"http://1.1.1.0:8181\"",
+			"kube_foo__qux__www1_example_org___a_path__bar_options": "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) && PathRegexp(/^(\\/a\\/path)/) && Method(\"OPTIONS\") && Weight(1) -> setResponseHeader(\"Access-Control-Allow-Origin\", \"https://app.example.org\") -> status(204) -> <shunt>",
+			"kube___catchall__www1_example_org____":                 "Host(/^(www1[.]example[.]org[.]?(:[0-9]+)?)$/) -> <shunt>",
+		},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+				testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0, ti.rule),
+			}}, &endpointList{
+				Items: testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"baz": 8181}),
+			}, &secretList{})
+			defer api.Close()
+			dc, err := New(Options{
+				KubernetesURL:            api.server.URL,
+				AutoHandleOptions:        ti.enabled,
+				AutoHandleOptionsHeaders: ti.headers,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
 
-		sort.Strings(expectedIDs)
-		sort.Strings(curIDs)
-		t.Errorf("number of routes %d doesn't match expected %d: %v", len(r), len(expected), cmp.Diff(expectedIDs, curIDs))
+			defer dc.Close()
 
-		return
-	}
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
 
-	for id, prettyExpectedRoute := range expected {
-		var found bool
+			checkPrettyRoutes(t, r, ti.expectedRoutes)
+		})
+	}
+}
+
+func TestDefaultBackendConflictPolicy(t *testing.T) {
+	services := &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"port": 8181}),
+		testService("foo", "baz", "1.2.3.5", map[string]int{"port": 8181}),
+	}}
+
+	endpoints := &endpointList{Items: append(
+		testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"port": 8181}),
+		testEndpoints("foo", "baz", "1.1.2", 1, map[string]int{"port": 8181})...,
+	)}
+
+	port := definitions.BackendPort{Value: "port"}
+	ingresses := &definitions.IngressList{Items: []*definitions.IngressItem{
+		testIngress("foo", "b", "baz", "", "", "", "", "", "", port, 1.0,
+			testRule("www.example.org", testPathRule("/api", "baz", port)),
+		),
+		testIngress("foo", "a", "bar", "", "", "", "", "", "", port, 1.0),
+	}}
+
+	for _, ti := range []struct {
+		msg            string
+		policy         DefaultBackendConflictPolicy
+		expectedRoutes map[string]string
+	}{{
+		msg:    "ignore",
+		policy: DefaultBackendConflictIgnore,
+		expectedRoutes: map[string]string{
+			"kube_foo__a______":                       "http://1.1.1.0:8181",
+			"kube_foo__b______":                       "http://1.1.2.0:8181",
+			"kube_foo__b__www_example_org___api__baz": "http://1.1.2.0:8181",
+			"kube___catchall__www_example_org____":    "",
+		},
+	}, {
+		msg:    "first wins",
+		policy: DefaultBackendConflictFirstWins,
+		expectedRoutes: map[string]string{
+			"kube_foo__a______":                       "http://1.1.1.0:8181",
+			"kube_foo__b__www_example_org___api__baz": "http://1.1.2.0:8181",
+			"kube___catchall__www_example_org____":    "",
+		},
+	}, {
+		msg:    "error",
+		policy: DefaultBackendConflictError,
+		expectedRoutes: map[string]string{
+			"kube_foo__b__www_example_org___api__baz": "http://1.1.2.0:8181",
+			"kube___catchall__www_example_org____":    "",
+		},
+	}, {
+		msg:    "per host",
+		policy: DefaultBackendConflictPerHost,
+		expectedRoutes: map[string]string{
+			"kube_foo__a______":                       "http://1.1.1.0:8181",
+			"kube_foo__b__www_example_org____":        "http://1.1.2.0:8181",
+			"kube_foo__b__www_example_org___api__baz": "http://1.1.2.0:8181",
+		},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			api := newTestAPIWithEndpoints(t, services, ingresses, endpoints, &secretList{})
+			defer api.Close()
+			dc, err := New(Options{
+				KubernetesURL:                api.server.URL,
+				DefaultBackendConflictPolicy: ti.policy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkRoutes(t, r, ti.expectedRoutes)
+		})
+	}
+}
+
+func checkPrettyRoutes(t *testing.T, r []*eskip.Route, expected map[string]string) {
+	if len(r) != len(expected) {
+		curIDs := make([]string, len(r))
+		expectedIDs := make([]string, len(expected))
+		for i := range r {
+			curIDs[i] = r[i].Id
+		}
+		j := 0
+		for k := range expected {
+			expectedIDs[j] = k
+			j++
+		}
+
+		sort.Strings(expectedIDs)
+		sort.Strings(curIDs)
+		t.Errorf("number of routes %d doesn't match expected %d: %v", len(r), len(expected), cmp.Diff(expectedIDs, curIDs))
+
+		return
+	}
+
+	for id, prettyExpectedRoute := range expected {
+		var found bool
 		for _, ri := range r {
 			if ri.Id == id {
 				prettyR := ri.Print(eskip.PrettyPrintInfo{})
@@ -2884,7 +3504,7 @@ func TestCreateEastWestRoute(t *testing.T) {
 		expectedID: "kubeew_foo__qux__www3_example_org___a_path__bar",
 	}} {
 		t.Run(ti.msg, func(t *testing.T) {
-			ewr := createEastWestRouteIng(defaultEastWestDomain, "foo", "qux", ti.route)
+			ewr := createEastWestRouteIng(newEastWestHosts(Options{}), defaultEastWestDomain, "foo", "qux", ti.route)
 			if ewr.Id != ti.expectedID {
 				t.Errorf("Failed to create east west route ID, %s, but expected %s", ewr.Id, ti.expectedID)
 			}
@@ -2958,7 +3578,7 @@ func TestCreateEastWestRouteOverwriteDomain(t *testing.T) {
 			}
 
 			ing := kube.ingress
-			ewr := createEastWestRouteIng(ing.kubernetesEastWestDomain, ti.name, ti.namespace, ti.route)
+			ewr := createEastWestRouteIng(newEastWestHosts(Options{}), ing.kubernetesEastWestDomain, ti.name, ti.namespace, ti.route)
 			if ewr.Id != ti.expectedID {
 				t.Errorf("Failed to create east west route ID, %s, but expected %s", ewr.Id, ti.expectedID)
 			}
@@ -2972,6 +3592,72 @@ func TestCreateEastWestRouteOverwriteDomain(t *testing.T) {
 	}
 }
 
+func TestEastWestHostLowercase(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("Foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+		testIngress("Foo", "Qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+			testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+		),
+	}}, &endpointList{
+		Items: testEndpoints("Foo", "bar", "1.1.1", 1, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	for _, test := range []struct {
+		order     EastWestHostOrder
+		lowercase bool
+		host      string
+	}{{
+		order: EastWestNameFirst,
+		host:  "Qux.Foo.skipper.cluster.local",
+	}, {
+		order: EastWestNamespaceFirst,
+		host:  "Foo.Qux.skipper.cluster.local",
+	}, {
+		order:     EastWestNameFirst,
+		lowercase: true,
+		host:      "qux.foo.skipper.cluster.local",
+	}, {
+		order:     EastWestNamespaceFirst,
+		lowercase: true,
+		host:      "foo.qux.skipper.cluster.local",
+	}} {
+		dc, err := New(Options{
+			KubernetesURL:            api.server.URL,
+			KubernetesEnableEastWest: true,
+			EastWestHostOrder:        test.order,
+			LowercaseEastWestHosts:   test.lowercase,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer dc.Close()
+
+		r, err := dc.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found bool
+		for _, ri := range r {
+			if !isEastWestRouteID(ri.Id) {
+				continue
+			}
+
+			found = true
+			if len(ri.HostRegexps) != 1 || !regexp.MustCompile(ri.HostRegexps[0]).MatchString(test.host) {
+				t.Errorf("expected the east-west route %s to match %s, got: %v", ri.Id, test.host, ri.HostRegexps)
+			}
+		}
+
+		if !found {
+			t.Error("east-west route not found")
+		}
+	}
+}
+
 func TestSkipperDefaultFilters(t *testing.T) {
 	api := newTestAPI(t, nil, &definitions.IngressList{})
 	defer api.Close()
@@ -3168,6 +3854,127 @@ func TestCertificateRegistry(t *testing.T) {
 	})
 }
 
+func TestIngressV1TLSSecretWithoutCertificateRegistry(t *testing.T) {
+	testTLSIngress := func(secretName string) *definitions.IngressV1List {
+		return &definitions.IngressV1List{Items: []*definitions.IngressV1Item{{
+			Metadata: &definitions.Metadata{Namespace: "namespace1", Name: "ingress1"},
+			Spec: &definitions.IngressV1Spec{
+				IngressTLS: []*definitions.TLSV1{{Hosts: []string{"www.example.org"}, SecretName: secretName}},
+			},
+		}}}
+	}
+
+	for _, test := range []struct {
+		title      string
+		secretName string
+		missing    bool
+	}{{
+		title:      "existing secret",
+		secretName: "secret1",
+	}, {
+		title:      "missing secret",
+		secretName: "secret-missing",
+		missing:    true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{}, testEndpointList(), testSecrets())
+			api.ingressesV1 = testTLSIngress(test.secretName)
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL, KubernetesIngressV1: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			if _, err := dc.LoadAll(); err != nil {
+				t.Fatal(err)
+			}
+
+			logged := strings.Contains(buf.String(), "TLS secret secret-missing not found in namespace namespace1")
+			if logged != test.missing {
+				t.Errorf("unexpected missing secret diagnostics, expected: %v, got: %v, log: %s", test.missing, logged, buf.String())
+			}
+		})
+	}
+}
+
+func testTLSCertificate(t *testing.T, notBefore time.Time) *tls.Certificate {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Skipper Test"}},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTLSConflictPolicy(t *testing.T) {
+	older := testTLSCertificate(t, time.Now().Add(-time.Hour))
+	newer := testTLSCertificate(t, time.Now())
+
+	// the ingress sorting first by namespace and name declares the older certificate
+	first := hostCert{
+		owner:  definitions.ResourceID{Namespace: "default", Name: "a"},
+		secret: definitions.ResourceID{Namespace: "default", Name: "secret-a"},
+		cert:   older,
+	}
+
+	second := hostCert{
+		owner:  definitions.ResourceID{Namespace: "default", Name: "b"},
+		secret: definitions.ResourceID{Namespace: "default", Name: "secret-b"},
+		cert:   newer,
+	}
+
+	for _, tc := range []struct {
+		policy   TLSConflictPolicy
+		expected *tls.Certificate
+	}{{
+		policy:   TLSConflictNewest,
+		expected: newer,
+	}, {
+		policy:   TLSConflictFirstWins,
+		expected: older,
+	}, {
+		policy:   TLSConflictError,
+		expected: nil,
+	}} {
+		for _, order := range [][]hostCert{{first, second}, {second, first}} {
+			r := certregistry.NewCertRegistry()
+			ing := &ingress{tlsConflictPolicy: tc.policy}
+			ing.configureHostCerts(hostCerts{"example.org": order}, r)
+
+			cert, err := r.GetCertFromHello(&tls.ClientHelloInfo{ServerName: "example.org"})
+			require.NoError(t, err)
+			assert.Same(t, tc.expected, cert, "policy: %d, first owner: %s", tc.policy, order[0].owner.Name)
+		}
+	}
+
+	t.Run("same secret in multiple ingresses is not a conflict", func(t *testing.T) {
+		r := certregistry.NewCertRegistry()
+		ing := &ingress{tlsConflictPolicy: TLSConflictError}
+		shared := second
+		shared.owner = first.owner
+		ing.configureHostCerts(hostCerts{"example.org": {second, shared}}, r)
+
+		cert, err := r.GetCertFromHello(&tls.ClientHelloInfo{ServerName: "example.org"})
+		require.NoError(t, err)
+		assert.Same(t, newer, cert)
+	})
+}
+
 type mockSecretProvider string
 
 func (sp mockSecretProvider) GetSecret(string) ([]byte, bool) {
@@ -3179,3 +3986,435 @@ func (mockSecretProvider) Add(string) error {
 }
 
 func (mockSecretProvider) Close() {}
+
+func TestAllowedHosts(t *testing.T) {
+	ing := testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+		testRule("a.example.org", testPathRule("/a", "bar", definitions.BackendPort{Value: "baz"})),
+		testRule("c.example.org", testPathRule("/c", "bar", definitions.BackendPort{Value: "baz"})),
+		testRule("", testPathRule("/api", "bar", definitions.BackendPort{Value: "baz"})),
+	)
+	ing.Metadata.Annotations[skipperAllowedHostsAnnotationKey] = "a.example.org, B.example.org"
+
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+		Items: testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	dc, err := New(Options{KubernetesURL: api.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	r, err := dc.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := func(host string) bool {
+		for _, ri := range r {
+			if ri.BackendType == eskip.ShuntBackend {
+				continue
+			}
+
+			for _, rx := range ri.HostRegexps {
+				if regexp.MustCompile(rx).MatchString(host) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	for _, host := range []string{"a.example.org", "b.example.org", "b.example.org:443"} {
+		if !matches(host) {
+			t.Errorf("expected a route matching the allowed host %s", host)
+		}
+	}
+
+	for _, host := range []string{"c.example.org", "www.example.org", "a.example.org.evil.org"} {
+		if matches(host) {
+			t.Errorf("expected no route matching the host %s", host)
+		}
+	}
+
+	for _, ri := range r {
+		if ri.BackendType != eskip.ShuntBackend && len(ri.HostRegexps) == 0 {
+			t.Errorf("expected a host predicate on route %s", ri.Id)
+		}
+	}
+}
+
+func TestAllowedCIDRs(t *testing.T) {
+	for _, reverse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("reverse source predicate: %v", reverse), func(t *testing.T) {
+			ing := testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+				testRule("www.example.org", testPathRule("/api", "bar", definitions.BackendPort{Value: "baz"})),
+			)
+			ing.Metadata.Annotations[skipperAllowedCIDRsAnnotationKey] = "10.0.0.0/8, 192.168.0.0/16"
+
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+				Items: testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"baz": 8181}),
+			}, &secretList{})
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL, ReverseSourcePredicate: reverse})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rt := routing.New(routing.Options{
+				DataClients:    []routing.DataClient{testdataclient.New(r)},
+				FilterRegistry: builtin.MakeRegistry(),
+				Predicates:     []routing.PredicateSpec{source.New(), source.NewFromLast()},
+				PollTimeout:    12 * time.Millisecond,
+			})
+			defer rt.Close()
+
+			for addr, routeID := range map[string]string{
+				"10.1.2.3":    "kube_foo__qux__www_example_org___api__bar",
+				"192.168.0.1": "kube_foo__qux__www_example_org___api__bar",
+				"172.16.0.1":  "kube_foo__qux__www_example_org___api__bar_denied",
+				"8.8.8.8":     "kube_foo__qux__www_example_org___api__bar_denied",
+			} {
+				req := &http.Request{
+					URL:        &url.URL{Path: "/api"},
+					Host:       "www.example.org",
+					RemoteAddr: "127.0.0.1:34567",
+					Header:     http.Header{"X-Forwarded-For": []string{addr}},
+				}
+
+				var route *routing.Route
+				for i := 0; i < 100 && route == nil; i++ {
+					route, _ = rt.Route(req)
+					if route == nil {
+						time.Sleep(12 * time.Millisecond)
+					}
+				}
+
+				if route == nil {
+					t.Errorf("no route found for %s", addr)
+					continue
+				}
+
+				if route.Id != routeID {
+					t.Errorf("expected the request from %s to be routed to %s, got: %s", addr, routeID, route.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultLoadBalancerAlgorithm(t *testing.T) {
+	for _, ti := range []struct {
+		msg        string
+		algorithm  string
+		annotation string
+		expected   string
+	}{{
+		msg:      "not configured",
+		expected: "roundRobin",
+	}, {
+		msg:       "configured",
+		algorithm: "consistentHash",
+		expected:  "consistentHash",
+	}, {
+		msg:        "annotation overrides the configured default",
+		algorithm:  "consistentHash",
+		annotation: "random",
+		expected:   "random",
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			ing := testIngress("foo", "qux", "", "", "", "", "", "", ti.annotation, definitions.BackendPort{}, 1.0,
+				testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+			)
+
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+				Items: testEndpoints("foo", "bar", "1.1.1", 2, map[string]int{"baz": 8181}),
+			}, &secretList{})
+			defer api.Close()
+
+			dc, err := New(Options{
+				KubernetesURL:                api.server.URL,
+				DefaultLoadBalancerAlgorithm: ti.algorithm,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var found bool
+			for _, ri := range r {
+				if ri.BackendType != eskip.LBBackend {
+					continue
+				}
+
+				found = true
+				if ri.LBAlgorithm != ti.expected {
+					t.Errorf("expected %s algorithm of route %s, got: %s", ti.expected, ri.Id, ri.LBAlgorithm)
+				}
+			}
+
+			if !found {
+				t.Error("load balanced route not found")
+			}
+		})
+	}
+
+	if _, err := New(Options{DefaultLoadBalancerAlgorithm: "fastest"}); err == nil {
+		t.Error("failed to fail creating the client with an invalid default load balancer algorithm")
+	}
+}
+
+func TestBackendHeaderName(t *testing.T) {
+	ing := testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+		testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+	)
+
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+		Items: testEndpoints("foo", "bar", "1.1.1", 2, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	for _, name := range []string{"", "X-Backend-Endpoint"} {
+		dc, err := New(Options{
+			KubernetesURL:      api.server.URL,
+			ProvideHealthcheck: true,
+			BackendHeaderName:  name,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer dc.Close()
+
+		r, err := dc.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found bool
+		for _, ri := range r {
+			var headerFilters []*eskip.Filter
+			for _, f := range ri.Filters {
+				if f.Name == filters.BackendEndpointHeaderName {
+					headerFilters = append(headerFilters, f)
+				}
+			}
+
+			if ri.BackendType != eskip.LBBackend || name == "" {
+				if len(headerFilters) != 0 {
+					t.Errorf("unexpected backend header filter on route %s", ri.Id)
+				}
+
+				continue
+			}
+
+			found = true
+			if len(headerFilters) != 1 || !reflect.DeepEqual(headerFilters[0].Args, []interface{}{name}) {
+				t.Errorf("expected a backend header filter with %s on route %s, got: %v", name, ri.Id, headerFilters)
+			}
+		}
+
+		if name != "" && !found {
+			t.Error("load balanced route not found")
+		}
+	}
+}
+
+func TestNormalizePaths(t *testing.T) {
+	for _, ti := range []struct {
+		msg       string
+		normalize bool
+		pathMode  string
+		expected  string
+		matches   bool
+	}{{
+		msg:      "disabled",
+		expected: "^(foo)",
+	}, {
+		msg:       "enabled",
+		normalize: true,
+		expected:  "^(/foo)",
+		matches:   true,
+	}, {
+		msg:       "enabled, path regexp mode",
+		normalize: true,
+		pathMode:  "path-regexp",
+		expected:  "foo",
+		matches:   true,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			ing := testIngress("foo", "qux", "", "", "", "", "", ti.pathMode, "", definitions.BackendPort{}, 1.0,
+				testRule("www.example.org", testPathRule("foo", "bar", definitions.BackendPort{Value: "baz"})),
+			)
+
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+				Items: testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"baz": 8181}),
+			}, &secretList{})
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL, NormalizePaths: ti.normalize})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var found bool
+			for _, ri := range r {
+				if len(ri.PathRegexps) != 1 {
+					continue
+				}
+
+				found = true
+				if ri.PathRegexps[0] != ti.expected {
+					t.Errorf("expected path regexp %s, got: %s", ti.expected, ri.PathRegexps[0])
+				}
+
+				if m := regexp.MustCompile(ri.PathRegexps[0]).MatchString("/foo"); m != ti.matches {
+					t.Errorf("expected path regexp %s matching /foo: %t, got: %t", ri.PathRegexps[0], ti.matches, m)
+				}
+			}
+
+			if !found {
+				t.Error("route with path regexp not found")
+			}
+		})
+	}
+}
+
+type testLatencyProvider map[string]time.Duration
+
+func (p testLatencyProvider) Latency(endpoint string) (time.Duration, bool) {
+	l, ok := p[endpoint]
+	return l, ok
+}
+
+func TestEndpointLatencyProvider(t *testing.T) {
+	for _, ti := range []struct {
+		msg      string
+		provider EndpointLatencyProvider
+		expected []string
+	}{{
+		msg: "no provider",
+		expected: []string{
+			"http://1.1.1.0:8181",
+			"http://1.1.1.1:8181",
+			"http://1.1.1.2:8181",
+		},
+	}, {
+		msg:      "no known latency",
+		provider: testLatencyProvider{},
+		expected: []string{
+			"http://1.1.1.0:8181",
+			"http://1.1.1.1:8181",
+			"http://1.1.1.2:8181",
+		},
+	}, {
+		msg: "ordered and weighted by latency",
+		provider: testLatencyProvider{
+			"http://1.1.1.0:8181": 30 * time.Millisecond,
+			"http://1.1.1.1:8181": 10 * time.Millisecond,
+		},
+		expected: []string{
+			"http://1.1.1.1:8181",
+			"http://1.1.1.1:8181",
+			"http://1.1.1.1:8181",
+			"http://1.1.1.0:8181",
+			"http://1.1.1.2:8181",
+		},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+				testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+					testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+				),
+			}}, &endpointList{
+				Items: testEndpoints("foo", "bar", "1.1.1", 3, map[string]int{"baz": 8181}),
+			}, &secretList{})
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL, EndpointLatencyProvider: ti.provider})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var found bool
+			for _, ri := range r {
+				if ri.BackendType != eskip.LBBackend {
+					continue
+				}
+
+				found = true
+				if !reflect.DeepEqual(ri.LBEndpoints, ti.expected) {
+					t.Errorf("expected endpoints %v, got: %v", ti.expected, ri.LBEndpoints)
+				}
+			}
+
+			if !found {
+				t.Error("load balanced route not found")
+			}
+		})
+	}
+}
+
+func TestInvalidInspectionFilter(t *testing.T) {
+	if _, err := New(Options{InspectionFilter: `lua("inspect.lua") ->`}); err == nil {
+		t.Error("failed to fail creating the client with an invalid inspection filter")
+	}
+}
+
+func TestDiagnosticsLoggedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.Out = &buf
+	d := newDiagnostics(log.NewEntry(logger))
+
+	d.Errorf("Service %s has no ports", "foo")
+	d.Errorf("Service %s has no ports", "foo")
+	d.Errorf("Service %s has no ports", "bar")
+
+	if n := strings.Count(buf.String(), "has no ports"); n != 2 {
+		t.Errorf("expected 2 diagnostics, got %d: %s", n, buf.String())
+	}
+}
@@ -27,6 +27,8 @@ type namespace struct {
 	routeGroups []byte
 	endpoints   []byte
 	secrets     []byte
+	nodes       []byte
+	pods        []byte
 }
 
 type api struct {
@@ -42,7 +44,7 @@ func NewAPI(o TestAPIOptions, specs ...io.Reader) (*api, error) {
 	a := &api{
 		namespaces: make(map[string]namespace),
 		pathRx: regexp.MustCompile(
-			"(/namespaces/([^/]+))?/(services|ingresses|routegroups|endpoints|secrets)",
+			"(/namespaces/([^/]+))?/(services|ingresses|routegroups|endpoints|secrets|nodes|pods)",
 		),
 	}
 
@@ -163,6 +165,10 @@ func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		b = ns.endpoints
 	case "secrets":
 		b = ns.secrets
+	case "nodes":
+		b = ns.nodes
+	case "pods":
+		b = ns.pods
 	default:
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -192,6 +198,14 @@ func initNamespace(kinds map[string][]interface{}) (ns namespace, err error) {
 		return
 	}
 
+	if err = itemsJSON(&ns.nodes, kinds["Node"]); err != nil {
+		return
+	}
+
+	if err = itemsJSON(&ns.pods, kinds["Pod"]); err != nil {
+		return
+	}
+
 	return
 }
 
@@ -35,6 +35,7 @@ type kubeOptionsParser struct {
 	IngressV1                bool               `yaml:"ingressv1"`
 	EastWest                 bool               `yaml:"eastWest"`
 	EastWestDomain           string             `yaml:"eastWestDomain"`
+	EastWestHostOrder        string             `yaml:"eastWestHostOrder"`
 	EastWestRangeDomains     []string           `yaml:"eastWestRangeDomains"`
 	EastWestRangePredicates  []*eskip.Predicate `yaml:"eastWestRangePredicatesAppend"`
 	HTTPSRedirect            bool               `yaml:"httpsRedirect"`
@@ -42,8 +43,20 @@ type kubeOptionsParser struct {
 	BackendNameTracingTag    bool               `yaml:"backendNameTracingTag"`
 	OnlyAllowedExternalNames bool               `yaml:"onlyAllowedExternalNames"`
 	AllowedExternalNames     []string           `yaml:"allowedExternalNames"`
+	AllowLocalExternalNames  bool               `yaml:"allowLocalExternalNames"`
 	IngressClass             string             `yaml:"kubernetes-ingress-class"`
 	KubernetesEnableTLS      bool               `yaml:"kubernetes-enable-tls"`
+	StrictAnnotationParsing  bool               `yaml:"strictAnnotationParsing"`
+	NodeCapacityLabel        string             `yaml:"nodeCapacityLabel"`
+	RejectDuplicatePaths     bool               `yaml:"rejectDuplicatePaths"`
+	StartupNotReadyBehavior  string             `yaml:"startupNotReadyBehavior"`
+	HostMatchPort            string             `yaml:"hostMatchPort"`
+	HostTrailingDot          string             `yaml:"hostTrailingDot"`
+	TLSConflictPolicy        string             `yaml:"tlsConflictPolicy"`
+	LenientListParsing       bool               `yaml:"lenientListParsing"`
+	NormalizePaths           bool               `yaml:"normalizePaths"`
+	DefaultSecurityHeaders   bool               `yaml:"defaultSecurityHeaders"`
+	InspectionFilter         string             `yaml:"inspectionFilter"`
 }
 
 func baseNoExt(n string) string {
@@ -218,12 +231,43 @@ func testFixture(t *testing.T, f fixtureSet) {
 		o.KubernetesIngressV1 = kop.IngressV1
 		o.KubernetesEnableEastWest = kop.EastWest
 		o.KubernetesEastWestDomain = kop.EastWestDomain
+		if kop.EastWestHostOrder == "namespace-first" {
+			o.EastWestHostOrder = kubernetes.EastWestNamespaceFirst
+		}
 		o.KubernetesEastWestRangeDomains = kop.EastWestRangeDomains
 		o.KubernetesEastWestRangePredicates = kop.EastWestRangePredicates
 		o.ProvideHTTPSRedirect = kop.HTTPSRedirect
 		o.HTTPSRedirectCode = kop.HTTPSRedirectCode
 		o.BackendNameTracingTag = kop.BackendNameTracingTag
 		o.IngressClass = kop.IngressClass
+		o.StrictAnnotationParsing = kop.StrictAnnotationParsing
+		o.NodeCapacityLabel = kop.NodeCapacityLabel
+		o.RejectDuplicatePaths = kop.RejectDuplicatePaths
+		o.LenientListParsing = kop.LenientListParsing
+		o.NormalizePaths = kop.NormalizePaths
+		o.DefaultSecurityHeaders = kop.DefaultSecurityHeaders
+		o.InspectionFilter = kop.InspectionFilter
+		o.HostMatchPort = kop.HostMatchPort
+
+		switch kop.StartupNotReadyBehavior {
+		case "route-anyway":
+			o.StartupNotReadyBehavior = kubernetes.NotReadyRouteAnyway
+		case "drop":
+			o.StartupNotReadyBehavior = kubernetes.NotReadyDrop
+		}
+
+		switch kop.HostTrailingDot {
+		case "reject":
+			o.HostTrailingDot = kubernetes.HostTrailingDotReject
+		case "normalize":
+			o.HostTrailingDot = kubernetes.HostTrailingDotNormalize
+		}
+		switch kop.TLSConflictPolicy {
+		case "first-wins":
+			o.TLSConflictPolicy = kubernetes.TLSConflictFirstWins
+		case "error":
+			o.TLSConflictPolicy = kubernetes.TLSConflictError
+		}
 		o.CertificateRegistry = cr
 
 		aen, err := compileRegexps(kop.AllowedExternalNames)
@@ -233,6 +277,7 @@ func testFixture(t *testing.T, f fixtureSet) {
 
 		o.OnlyAllowedExternalNames = kop.OnlyAllowedExternalNames
 		o.AllowedExternalNames = aen
+		o.AllowLocalExternalNames = kop.AllowLocalExternalNames
 	}
 
 	o.KubernetesURL = s.URL
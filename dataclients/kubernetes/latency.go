@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// EndpointLatencyProvider provides the measured latency of the endpoints,
+// e.g. from an external monitoring system. It is used to prefer the lower
+// latency endpoints in the load balanced routes.
+type EndpointLatencyProvider interface {
+	// Latency returns the latency of an endpoint, in the format of the route
+	// backends, e.g. "http://10.2.9.103:8080". It returns false when the
+	// latency of the endpoint is not known.
+	Latency(endpoint string) (time.Duration, bool)
+}
+
+type endpointLatency struct {
+	endpoint string
+	count    int
+	latency  time.Duration
+	known    bool
+}
+
+// latencyWeighted orders the endpoints by their latency, the lowest first,
+// followed by the endpoints with unknown latency, and repeats them inversely
+// proportionally to their latency, relative to the highest known latency, up
+// to 64 times. The endpoints with unknown latency get weight 1. The weights
+// multiply the existing repetitions of the endpoints, e.g. those based on the
+// node capacity. When no latency is known, the endpoints are returned as is.
+func latencyWeighted(targets []string, p EndpointLatencyProvider) []string {
+	var (
+		distinct   []*endpointLatency
+		byEndpoint = make(map[string]*endpointLatency)
+		maxLatency time.Duration
+		anyKnown   bool
+	)
+
+	for _, t := range targets {
+		if el, ok := byEndpoint[t]; ok {
+			el.count++
+			continue
+		}
+
+		el := &endpointLatency{endpoint: t, count: 1}
+		el.latency, el.known = p.Latency(t)
+		if el.known {
+			anyKnown = true
+			if el.latency > maxLatency {
+				maxLatency = el.latency
+			}
+		}
+
+		byEndpoint[t] = el
+		distinct = append(distinct, el)
+	}
+
+	if !anyKnown || len(distinct) < 2 {
+		return targets
+	}
+
+	sort.SliceStable(distinct, func(i, j int) bool {
+		if distinct[i].known != distinct[j].known {
+			return distinct[i].known
+		}
+
+		return distinct[i].latency < distinct[j].latency
+	})
+
+	weighted := make([]string, 0, len(targets))
+	for _, el := range distinct {
+		w := 1
+		if el.known {
+			w = latencyWeight(el.latency, maxLatency)
+		}
+
+		for i := 0; i < el.count*w; i++ {
+			weighted = append(weighted, el.endpoint)
+		}
+	}
+
+	return weighted
+}
+
+func latencyWeight(latency, maxLatency time.Duration) int {
+	if latency <= 0 {
+		return maxNodeCapacityWeight
+	}
+
+	w := int(math.Round(float64(maxLatency) / float64(latency)))
+	if w < 1 {
+		return 1
+	}
+
+	if w > maxNodeCapacityWeight {
+		return maxNodeCapacityWeight
+	}
+
+	return w
+}
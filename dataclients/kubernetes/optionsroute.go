@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/predicates"
+)
+
+// defaultOptionsHeaders are the response headers of the generated OPTIONS
+// routes, when Options.AutoHandleOptionsHeaders is not set.
+var defaultOptionsHeaders = map[string]string{
+	"Access-Control-Allow-Origin":  "*",
+	"Access-Control-Allow-Methods": "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
+	"Access-Control-Allow-Headers": "*",
+}
+
+// optionsRouteKey identifies the distinct paths of the routes of a host, by
+// their path conditions and weight.
+func optionsRouteKey(r *eskip.Route) string {
+	return fmt.Sprint(r.Path, r.PathRegexps, optionsRoutePredicates(r))
+}
+
+// optionsRoutePredicates returns the path and weight predicates of a route,
+// to be copied to its OPTIONS route. Other predicates, e.g. Traffic, are not
+// relevant for the preflight requests.
+func optionsRoutePredicates(r *eskip.Route) []*eskip.Predicate {
+	var p []*eskip.Predicate
+	for _, pi := range r.Predicates {
+		switch pi.Name {
+		case predicates.PathName, predicates.PathSubtreeName, predicates.WeightName:
+			p = append(p, pi)
+		}
+	}
+
+	return p
+}
+
+func optionsRouteFilters(headers map[string]string) []*eskip.Filter {
+	if headers == nil {
+		headers = defaultOptionsHeaders
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var f []*eskip.Filter
+	for _, name := range names {
+		f = append(f, &eskip.Filter{
+			Name: filters.SetResponseHeaderName,
+			Args: []interface{}{name, headers[name]},
+		})
+	}
+
+	return append(f, &eskip.Filter{
+		Name: filters.StatusName,
+		Args: []interface{}{float64(http.StatusNoContent)},
+	})
+}
+
+// createOptionsRoutes creates a route for each distinct path of the routes
+// of a host, responding to the OPTIONS requests, e.g. CORS preflight
+// requests, without forwarding them to the backends. The routes match the
+// same host and path as the original ones, and take precedence due to their
+// additional Method predicate.
+func (ing *ingress) createOptionsRoutes(rs []*eskip.Route) []*eskip.Route {
+	var routes []*eskip.Route
+	seen := make(map[string]bool)
+	for _, r := range rs {
+		key := optionsRouteKey(r)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		routes = append(routes, &eskip.Route{
+			Id:          r.Id + "_options",
+			HostRegexps: r.HostRegexps,
+			Path:        r.Path,
+			PathRegexps: r.PathRegexps,
+			Method:      http.MethodOptions,
+			Predicates:  optionsRoutePredicates(r),
+			Filters:     optionsRouteFilters(ing.autoHandleOptionsHeaders),
+			BackendType: eskip.ShuntBackend,
+		})
+	}
+
+	return routes
+}
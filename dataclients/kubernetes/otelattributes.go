@@ -0,0 +1,50 @@
+package kubernetes
+
+import (
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperOtelAttributesAnnotationKey = "zalando.org/skipper-otel-attributes"
+
+// attribute keys are namespaced with dots, e.g. service.namespace, see
+// https://opentelemetry.io/docs/specs/semconv/general/naming/
+var otelAttributeKeyRx = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*(\.[a-zA-Z0-9_-]+)*$`)
+
+// parse the span attributes annotation, a comma separated list of key=value
+// pairs, and create a tracingTag filter for each pair. When any of the pairs
+// is invalid, no filters are created.
+func otelAttributesFilters(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+	val, ok := m.Annotations[skipperOtelAttributesAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var f []*eskip.Filter
+	keys := make(map[string]bool)
+	for _, pair := range strings.Split(val, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			kv = append(kv, "")
+		}
+
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if !otelAttributeKeyRx.MatchString(key) || value == "" || keys[key] {
+			logger.Errorf("Invalid %s annotation, comma separated list of unique key=value pairs expected, e.g. team=payments,tier=frontend: %q", skipperOtelAttributesAnnotationKey, val)
+			return nil
+		}
+
+		keys[key] = true
+		f = append(f, &eskip.Filter{
+			Name: filters.TracingTagName,
+			Args: []interface{}{key, value},
+		})
+	}
+
+	return f
+}
@@ -3,11 +3,18 @@ package kubernetes
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
 )
 
 func findPathPredicate(r *eskip.Route, name string) (*eskip.Predicate, error) {
@@ -398,3 +405,178 @@ func TestIngressSpecificMode(t *testing.T) {
 		t.Error("failed to load route with prefix path")
 	}
 }
+
+// PathSubtree routes don't need weights to prefer the more specific prefix,
+// because the routing tree selects the longest matching subtree before
+// evaluating the other predicates. The routes with path regexps get weights
+// proportional to the depth of the path.
+func TestNestedPrefixPaths(t *testing.T) {
+	for _, test := range []struct {
+		mode  string
+		outer string
+		inner string
+	}{{
+		mode:  pathPrefixString,
+		outer: "/a",
+		inner: "/a/b",
+	}, {
+		mode:  pathRegexpString,
+		outer: "^/a",
+		inner: "^/a/b",
+	}, {
+		mode:  kubernetesIngressModeString,
+		outer: "/a",
+		inner: "/a/b",
+	}} {
+		t.Run(test.mode, func(t *testing.T) {
+			api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+				testService("foo", "bar", "1.2.3.4", map[string]int{"port": 8181}),
+				testService("foo", "baz", "1.2.3.5", map[string]int{"port": 8181}),
+			}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+				testIngress("foo", "qux", "", "", "", "", "", test.mode, "", definitions.BackendPort{}, 1.0,
+					testRule(
+						"www.example.org",
+						testPathRule(test.outer, "bar", definitions.BackendPort{Value: "port"}),
+						testPathRule(test.inner, "baz", definitions.BackendPort{Value: "port"}),
+					),
+				),
+			}}, &endpointList{Items: append(
+				testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"port": 8181}),
+				testEndpoints("foo", "baz", "1.1.2", 1, map[string]int{"port": 8181})...,
+			)}, &secretList{})
+			defer api.Close()
+
+			dc, err := New(Options{KubernetesURL: api.server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer dc.Close()
+
+			r, err := dc.LoadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rt := routing.New(routing.Options{
+				DataClients: []routing.DataClient{testdataclient.New(r)},
+				PollTimeout: 12 * time.Millisecond,
+			})
+			defer rt.Close()
+
+			for path, backend := range map[string]string{
+				"/a":       "http://1.1.1.0:8181",
+				"/a/c":     "http://1.1.1.0:8181",
+				"/a/b":     "http://1.1.2.0:8181",
+				"/a/b/c":   "http://1.1.2.0:8181",
+				"/a/b/c/d": "http://1.1.2.0:8181",
+			} {
+				req := &http.Request{URL: &url.URL{Path: path}, Host: "www.example.org"}
+
+				var route *routing.Route
+				for i := 0; i < 100 && route == nil; i++ {
+					route, _ = rt.Route(req)
+					if route == nil {
+						time.Sleep(12 * time.Millisecond)
+					}
+				}
+
+				if route == nil {
+					t.Errorf("no route found for %s", path)
+					continue
+				}
+
+				if route.Backend != backend {
+					t.Errorf("expected %s to be routed to %s, got: %s", path, backend, route.Backend)
+				}
+			}
+		})
+	}
+}
+
+func TestGlobalPathPrefix(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"port": 8181}),
+		testService("foo", "baz", "1.2.3.5", map[string]int{"port": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{
+		testIngress("foo", "qux", "", "", "", "", "", pathPrefixString, "", definitions.BackendPort{}, 1.0,
+			testRule(
+				"www.example.org",
+				testPathRule("/foo", "bar", definitions.BackendPort{Value: "port"}),
+				testPathRule("/", "baz", definitions.BackendPort{Value: "port"}),
+			),
+		),
+	}}, &endpointList{Items: append(
+		testEndpoints("foo", "bar", "1.1.1", 1, map[string]int{"port": 8181}),
+		testEndpoints("foo", "baz", "1.1.2", 1, map[string]int{"port": 8181})...,
+	)}, &secretList{})
+	defer api.Close()
+
+	dc, err := New(Options{KubernetesURL: api.server.URL, GlobalPathPrefix: "/prefix/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	r, err := dc.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := routing.New(routing.Options{
+		DataClients:    []routing.DataClient{testdataclient.New(r)},
+		FilterRegistry: builtin.MakeRegistry(),
+		PollTimeout:    12 * time.Millisecond,
+	})
+	defer rt.Close()
+
+	for _, ti := range []struct {
+		path        string
+		backend     string
+		backendPath string
+	}{
+		{"/prefix/foo", "http://1.1.1.0:8181", "/foo"},
+		{"/prefix/foo/bar", "http://1.1.1.0:8181", "/foo/bar"},
+		{"/prefix/qux", "http://1.1.2.0:8181", "/qux"},
+		{"/prefix", "http://1.1.2.0:8181", "/"},
+		{"/foo", "", ""},
+		{"/prefixfoo", "", ""},
+	} {
+		req := &http.Request{URL: &url.URL{Path: ti.path}, Host: "www.example.org"}
+
+		var route *routing.Route
+		for i := 0; i < 100 && route == nil; i++ {
+			route, _ = rt.Route(req)
+			if route == nil && ti.backend != "" {
+				time.Sleep(12 * time.Millisecond)
+			}
+		}
+
+		if ti.backend == "" {
+			if route != nil {
+				t.Errorf("unexpected route found for %s: %s", ti.path, route.Id)
+			}
+
+			continue
+		}
+
+		if route == nil {
+			t.Errorf("no route found for %s", ti.path)
+			continue
+		}
+
+		if route.Backend != ti.backend {
+			t.Errorf("expected %s to be routed to %s, got: %s", ti.path, ti.backend, route.Backend)
+		}
+
+		ctx := &filtertest.Context{FRequest: req}
+		for _, f := range route.Filters {
+			f.Filter.Request(ctx)
+		}
+
+		if req.URL.Path != ti.backendPath {
+			t.Errorf("expected backend path %s for %s, got: %s", ti.backendPath, ti.path, req.URL.Path)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"sort"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+)
+
+type pod struct {
+	Meta   *definitions.Metadata `json:"metadata"`
+	Status *podStatus            `json:"status"`
+}
+
+type podStatus struct {
+	Conditions []*podCondition `json:"conditions"`
+}
+
+type podCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type podList struct {
+	Items []*pod `json:"items"`
+}
+
+// condition tells whether the pod has the condition of the given type with
+// the status "True".
+func (p *pod) condition(typ string) bool {
+	if p.Status == nil {
+		return false
+	}
+
+	for _, c := range p.Status.Conditions {
+		if c != nil && c.Type == typ {
+			return c.Status == "True"
+		}
+	}
+
+	return false
+}
+
+// podNamespaces returns the namespaces whose pods need to be loaded, because
+// the routes of their ingresses or services depend on the state of the pods.
+func podNamespaces(ingresses []*definitions.IngressItem, ingressesV1 []*definitions.IngressV1Item, services map[definitions.ResourceID]*service) []string {
+	m := make(map[string]bool)
+	endpointSelectorNamespaces(m, ingresses, ingressesV1)
+	endpointConditionNamespaces(m, services)
+
+	namespaces := make([]string, 0, len(m))
+	for ns := range m {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// selectAddresses returns the addresses whose target pod is accepted by the
+// match function.
+func selectAddresses(addresses []*address, namespace string, pods map[definitions.ResourceID]*pod, match func(*pod) bool) []*address {
+	var selected []*address
+	for _, a := range addresses {
+		ref := a.TargetRef
+		if ref == nil || ref.Kind != "Pod" {
+			continue
+		}
+
+		ns := ref.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+
+		p, ok := pods[newResourceID(ns, ref.Name)]
+		if ok && match(p) {
+			selected = append(selected, a)
+		}
+	}
+
+	return selected
+}
+
+// selectPods returns the endpoint with only those addresses, whose target pod
+// is accepted by the match function.
+func (ep endpoint) selectPods(pods map[definitions.ResourceID]*pod, match func(*pod) bool) endpoint {
+	var namespace string
+	if ep.Meta != nil {
+		namespace = ep.Meta.Namespace
+	}
+
+	subsets := make([]*subset, len(ep.Subsets))
+	for i, s := range ep.Subsets {
+		subsets[i] = &subset{
+			Addresses:         selectAddresses(s.Addresses, namespace, pods, match),
+			NotReadyAddresses: selectAddresses(s.NotReadyAddresses, namespace, pods, match),
+			Ports:             s.Ports,
+		}
+	}
+
+	return endpoint{Meta: ep.Meta, Subsets: subsets}
+}
@@ -0,0 +1,59 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperRatelimitKeyAnnotationKey = "zalando.org/skipper-ratelimit-key"
+
+// ratelimitKey is the configuration of the header keyed rate limit, defined
+// by the zalando.org/skipper-ratelimit-key annotation. The requests with the
+// same value of the header, e.g. the same API key, are counted as the same
+// client.
+type ratelimitKey struct {
+	Header string `json:"header"`
+	Rate   int    `json:"rate"`
+	Window string `json:"window"`
+}
+
+// parse ratelimit key annotation, and create a clientRatelimit filter keyed
+// by the configured header
+func ratelimitKeyFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperRatelimitKeyAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var rk ratelimitKey
+	if err := json.Unmarshal([]byte(val), &rk); err != nil {
+		logger.Errorf("Invalid %s annotation, failed to parse: %v", skipperRatelimitKeyAnnotationKey, err)
+		return nil
+	}
+
+	if rk.Header == "" {
+		logger.Errorf("Invalid %s annotation, header is required", skipperRatelimitKeyAnnotationKey)
+		return nil
+	}
+
+	if rk.Rate <= 0 {
+		logger.Errorf("Invalid %s annotation, positive rate expected: %d", skipperRatelimitKeyAnnotationKey, rk.Rate)
+		return nil
+	}
+
+	if d, err := time.ParseDuration(rk.Window); err != nil || d <= 0 {
+		logger.Errorf("Invalid %s annotation, positive window expected, e.g. 1m: %s", skipperRatelimitKeyAnnotationKey, rk.Window)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.ClientRatelimitName,
+		Args: []interface{}{float64(rk.Rate), rk.Window, http.CanonicalHeaderKey(rk.Header)},
+	}
+}
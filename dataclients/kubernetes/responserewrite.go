@@ -0,0 +1,46 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperResponseRewriteAnnotationKey = "zalando.org/skipper-response-rewrite"
+
+// responseRewrite is the configuration of the response body rewrite, defined
+// by the zalando.org/skipper-response-rewrite annotation. Every occurrence of
+// the match string in the response body is replaced with the replace string.
+type responseRewrite struct {
+	Match   string  `json:"match"`
+	Replace *string `json:"replace"`
+}
+
+// parse response rewrite annotation, and create a sed filter replacing the
+// literal match string in the response body
+func responseRewriteFilter(m *definitions.Metadata, logger *log.Entry) *eskip.Filter {
+	val, ok := m.Annotations[skipperResponseRewriteAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var rw responseRewrite
+	if err := json.Unmarshal([]byte(val), &rw); err != nil {
+		logger.Errorf("Invalid %s annotation, failed to parse: %v", skipperResponseRewriteAnnotationKey, err)
+		return nil
+	}
+
+	if rw.Match == "" || rw.Replace == nil {
+		logger.Errorf("Invalid %s annotation, match and replace are required: %s", skipperResponseRewriteAnnotationKey, val)
+		return nil
+	}
+
+	return &eskip.Filter{
+		Name: filters.SedName,
+		Args: []interface{}{regexp.QuoteMeta(rw.Match), *rw.Replace},
+	}
+}
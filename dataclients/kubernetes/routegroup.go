@@ -26,6 +26,7 @@ type routeGroupContext struct {
 	allowedExternalNames  []*regexp.Regexp
 	hostRx                string
 	eastWestDomain        string
+	eastWestHosts         eastWestHosts
 	routeGroup            *definitions.RouteGroupItem
 	hostRoutes            map[string][]*eskip.Route
 	defaultBackendTraffic map[string]*calculatedTraffic
@@ -36,6 +37,7 @@ type routeGroupContext struct {
 	eastWestEnabled       bool
 	hasEastWestHost       bool
 	backendNameTracingTag bool
+	defaultLBAlgorithm    string
 	internal              bool
 	provideHTTPSRedirect  bool
 }
@@ -282,7 +284,7 @@ func applyServiceBackend(ctx *routeGroupContext, backend *definitions.SkipperBac
 
 	r.BackendType = eskip.LBBackend
 	r.LBEndpoints = eps
-	r.LBAlgorithm = defaultLoadBalancerAlgorithm
+	r.LBAlgorithm = ctx.defaultLBAlgorithm
 	if backend.Algorithm != loadbalancer.None {
 		r.LBAlgorithm = backend.Algorithm.String()
 	}
@@ -335,7 +337,7 @@ func applyBackend(ctx *routeGroupContext, backend *definitions.SkipperBackend, r
 		}
 
 		r.LBEndpoints = backend.Endpoints
-		r.LBAlgorithm = defaultLoadBalancerAlgorithm
+		r.LBAlgorithm = ctx.defaultLBAlgorithm
 		if backend.Algorithm != loadbalancer.None {
 			r.LBAlgorithm = backend.Algorithm.String()
 		}
@@ -367,6 +369,7 @@ func appendEastWest(ctx *routeGroupContext, routes []*eskip.Route, current *eski
 	}
 
 	ewr := createEastWestRouteRG(
+		ctx.eastWestHosts,
 		ctx.routeGroup.Metadata.Name,
 		namespaceString(ctx.routeGroup.Metadata.Namespace),
 		ctx.eastWestDomain,
@@ -557,6 +560,9 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 	var rs []*eskip.Route
 	redirect := createRedirectInfo(r.options.ProvideHTTPSRedirect, r.options.HTTPSRedirectCode)
 
+	// the option is validated when creating the client
+	portRx, _ := hostPortRx(r.options.HostMatchPort, r.options.HostTrailingDot)
+
 	for _, rg := range s.routeGroups {
 		redirect.initCurrent(rg.Metadata)
 
@@ -589,17 +595,19 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				defaultFilters:        df,
 				routeGroup:            rg,
 				hosts:                 externalHosts,
-				hostRx:                createHostRx(externalHosts...),
+				hostRx:                createHostRxPort(portRx, externalHosts...),
 				hostRoutes:            make(map[string][]*eskip.Route),
 				hasEastWestHost:       hasEastWestHost(r.options.KubernetesEastWestDomain, externalHosts),
 				eastWestEnabled:       r.options.KubernetesEnableEastWest,
 				eastWestDomain:        r.options.KubernetesEastWestDomain,
+				eastWestHosts:         newEastWestHosts(r.options),
 				provideHTTPSRedirect:  provideRedirect,
 				httpsRedirectCode:     r.options.HTTPSRedirectCode,
 				backendsByName:        backends,
 				backendNameTracingTag: r.options.BackendNameTracingTag,
 				internal:              false,
 				allowedExternalNames:  r.options.AllowedExternalNames,
+				defaultLBAlgorithm:    r.options.DefaultLoadBalancerAlgorithm,
 			}
 
 			ri, err := transformRouteGroup(ctx)
@@ -614,7 +622,7 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				continue
 			}
 
-			catchAll := hostCatchAllRoutes(ctx.hostRoutes, func(host string) string {
+			catchAll := hostCatchAllRoutes(ctx.hostRoutes, portRx, func(host string) string {
 				// "catchall" won't conflict with any HTTP method
 				return rgRouteID("", toSymbol(host), "catchall", 0, 0, false)
 			})
@@ -630,12 +638,13 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				defaultFilters:        df,
 				routeGroup:            rg,
 				hosts:                 internalHosts,
-				hostRx:                createHostRx(internalHosts...),
+				hostRx:                createHostRxPort(portRx, internalHosts...),
 				hostRoutes:            make(map[string][]*eskip.Route),
 				backendsByName:        backends,
 				backendNameTracingTag: r.options.BackendNameTracingTag,
 				internal:              true,
 				allowedExternalNames:  r.options.AllowedExternalNames,
+				defaultLBAlgorithm:    r.options.DefaultLoadBalancerAlgorithm,
 			}
 
 			internalRi, err := transformRouteGroup(internalCtx)
@@ -650,7 +659,7 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				continue
 			}
 
-			catchAll := hostCatchAllRoutes(internalCtx.hostRoutes, func(host string) string {
+			catchAll := hostCatchAllRoutes(internalCtx.hostRoutes, portRx, func(host string) string {
 				// "catchall" won't conflict with any HTTP method
 				return rgRouteID("", toSymbol(host), "catchall", 0, 0, true)
 			})
@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperScheduleAnnotationKey = "zalando.org/skipper-schedule"
+
+// schedule is the configuration of the time of day based canary routing,
+// defined by the zalando.org/skipper-schedule annotation. Requests received
+// between from and to, in the local time of skipper, are routed to the
+// scheduled service, instead of the backends defined by the ingress rules.
+type schedule struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Service string `json:"service"`
+	Port    string `json:"port"`
+
+	cron []string
+}
+
+// parse schedule annotation
+func scheduleAnnotation(m *definitions.Metadata, logger *log.Entry) *schedule {
+	val, ok := m.Annotations[skipperScheduleAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var s schedule
+	if err := json.Unmarshal([]byte(val), &s); err != nil {
+		logger.Errorf("Invalid %s annotation, failed to parse: %v", skipperScheduleAnnotationKey, err)
+		return nil
+	}
+
+	if s.Service == "" || s.Port == "" {
+		logger.Errorf("Invalid %s annotation, service and port are required", skipperScheduleAnnotationKey)
+		return nil
+	}
+
+	from, err := minuteOfDay(s.From)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, invalid from time: %v", skipperScheduleAnnotationKey, err)
+		return nil
+	}
+
+	to, err := minuteOfDay(s.To)
+	if err != nil {
+		logger.Errorf("Invalid %s annotation, invalid to time: %v", skipperScheduleAnnotationKey, err)
+		return nil
+	}
+
+	if from == to {
+		logger.Errorf("Invalid %s annotation, empty time window: %s-%s", skipperScheduleAnnotationKey, s.From, s.To)
+		return nil
+	}
+
+	// windows over midnight are split in two
+	if from < to {
+		s.cron = cronWindow(from, to)
+	} else {
+		s.cron = append(cronWindow(from, 24*60), cronWindow(0, to)...)
+	}
+
+	return &s
+}
+
+// minuteOfDay parses a time of day in the HH:MM format.
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func cronRange(from, to int) string {
+	if from == to {
+		return strconv.Itoa(from)
+	}
+
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+// cronWindow returns the cron expressions matching the minutes of the day
+// in [from, to). The whole hours are matched by a single expression, while
+// the partial hours at the start and at the end of the window get their own.
+func cronWindow(from, to int) []string {
+	var expr []string
+	for from < to {
+		hour, minute := from/60, from%60
+		if minute == 0 && to-from >= 60 {
+			last := to/60 - 1
+			expr = append(expr, fmt.Sprintf("* %s * * *", cronRange(hour, last)))
+			from = (last + 1) * 60
+			continue
+		}
+
+		end := (hour + 1) * 60
+		if to < end {
+			end = to
+		}
+
+		expr = append(expr, fmt.Sprintf("%s %d * * *", cronRange(minute, end-hour*60-1), hour))
+		from = end
+	}
+
+	return expr
+}
+
+func (s *schedule) backendPort() definitions.BackendPortV1 {
+	if n, err := strconv.Atoi(s.Port); err == nil {
+		return definitions.BackendPortV1{Number: n}
+	}
+
+	return definitions.BackendPortV1{Name: s.Port}
+}
+
+// addScheduleRoutesV1 creates the routes to the scheduled service for the host
+// and path of the path rule, matching the time window with Cron predicates.
+// When the window is not made of whole hours, or it spans over midnight, one
+// route is created for each of its parts. The routes precede the routes of the
+// path rule, also when they split the traffic between weighted backends.
+func (ing *ingress) addScheduleRoutesV1(ic ingressContext, host string, prule *definitions.PathRuleV1) error {
+	s := ic.schedule
+	for i, c := range s.cron {
+		kind := "schedule"
+		if len(s.cron) > 1 {
+			kind = fmt.Sprintf("schedule%d", i)
+		}
+
+		p := &eskip.Predicate{Name: predicates.CronName, Args: []interface{}{c}}
+		if err := ing.addCanaryRouteV1(ic, host, prule, kind, s.Service, s.backendPort(), p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const skipperSecurityHeadersAnnotationKey = "zalando.org/skipper-security-headers"
+
+var securityHeaders = [][]interface{}{
+	{"Strict-Transport-Security", "max-age=31536000; includeSubDomains"},
+	{"X-Content-Type-Options", "nosniff"},
+	{"X-Frame-Options", "DENY"},
+}
+
+// securityHeaderFilters returns the filters setting the security response
+// headers, when the DefaultSecurityHeaders option is enabled, and the ingress
+// doesn't opt out with the zalando.org/skipper-security-headers annotation
+// set to "false".
+func (ing *ingress) securityHeaderFilters(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+	if !ing.defaultSecurityHeaders {
+		return nil
+	}
+
+	switch val, ok := m.Annotations[skipperSecurityHeadersAnnotationKey]; {
+	case !ok || val == "true":
+	case val == "false":
+		return nil
+	default:
+		logger.Errorf("Invalid %s annotation, true or false expected: %s", skipperSecurityHeadersAnnotationKey, val)
+	}
+
+	f := make([]*eskip.Filter, len(securityHeaders))
+	for i, h := range securityHeaders {
+		f[i] = &eskip.Filter{
+			Name: filters.SetResponseHeaderName,
+			Args: h,
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperShadowEveryAnnotationKey = "zalando.org/skipper-shadow-every"
+
+// shadowEvery is the configuration of the shadow traffic, defined by the
+// zalando.org/skipper-shadow-every annotation. Every n-th request of the
+// ingress routes is copied to the shadow service, and the responses of the
+// shadow service are dropped.
+type shadowEvery struct {
+	N       int    `json:"n"`
+	Service string `json:"service"`
+	Port    string `json:"port"`
+
+	key string
+}
+
+// parse shadow every annotation
+func shadowEveryAnnotation(m *definitions.Metadata, logger *log.Entry) *shadowEvery {
+	val, ok := m.Annotations[skipperShadowEveryAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var s shadowEvery
+	if err := json.Unmarshal([]byte(val), &s); err != nil {
+		logger.Errorf("error while parsing %s annotation: %v", skipperShadowEveryAnnotationKey, err)
+		return nil
+	}
+
+	if s.Service == "" || s.Port == "" {
+		logger.Errorf("invalid %s annotation, service and port are required", skipperShadowEveryAnnotationKey)
+		return nil
+	}
+
+	if s.N < 1 {
+		logger.Errorf("invalid %s annotation, n must be at least 1: %d", skipperShadowEveryAnnotationKey, s.N)
+		return nil
+	}
+
+	// the tee key identifies the shadow routes of the ingress
+	s.key = m.Namespace + "_" + m.Name + "_shadow"
+	return &s
+}
+
+func (s *shadowEvery) backendPort() definitions.BackendPortV1 {
+	if n, err := strconv.Atoi(s.Port); err == nil {
+		return definitions.BackendPortV1{Number: n}
+	}
+
+	return definitions.BackendPortV1{Name: s.Port}
+}
+
+func (s *shadowEvery) filter() *eskip.Filter {
+	return &eskip.Filter{
+		Name: filters.TeeLoopbackName,
+		Args: []interface{}{s.key, float64(s.N)},
+	}
+}
+
+func (s *shadowEvery) predicate() *eskip.Predicate {
+	return &eskip.Predicate{
+		Name: predicates.TeeName,
+		Args: []interface{}{s.key},
+	}
+}
+
+// addShadowRouteV1 creates a route to the shadow service for the host and path
+// of the path rule, matching the requests copied by the teeLoopback filter of
+// the ingress routes. The route precedes the routes of the path rule, also when
+// they split the traffic between weighted backends, so that the copies are not
+// routed back to the backends of the ingress.
+func (ing *ingress) addShadowRouteV1(ic ingressContext, host string, prule *definitions.PathRuleV1) error {
+	s := ic.shadowEvery
+	return ing.addCanaryRouteV1(ic, host, prule, "shadow", s.Service, s.backendPort(), s.predicate())
+}
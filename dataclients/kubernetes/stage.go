@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"net/http"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	skipperStageAnnotationKey       = "zalando.org/skipper-stage"
+	skipperStageHeaderAnnotationKey = "zalando.org/skipper-stage-header"
+
+	stageTracingTag = "stage"
+)
+
+var stageRx = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// parse stage annotation, and create the filters tagging the routes of the
+// ingress with the stage: a tracingTag filter, and, when the stage header
+// annotation is set, a setResponseHeader filter
+func stageFilters(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+	stage, ok := m.Annotations[skipperStageAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	if !stageRx.MatchString(stage) {
+		logger.Errorf("Invalid %s annotation, letters, digits, '.', '_' and '-' expected: %q", skipperStageAnnotationKey, stage)
+		return nil
+	}
+
+	f := []*eskip.Filter{{
+		Name: filters.TracingTagName,
+		Args: []interface{}{stageTracingTag, stage},
+	}}
+
+	if header, ok := m.Annotations[skipperStageHeaderAnnotationKey]; ok {
+		if !stageRx.MatchString(header) {
+			logger.Errorf("Invalid %s annotation, header name expected: %q", skipperStageHeaderAnnotationKey, header)
+			return f
+		}
+
+		f = append(f, &eskip.Filter{
+			Name: filters.SetResponseHeaderName,
+			Args: []interface{}{http.CanonicalHeaderKey(header), stage},
+		})
+	}
+
+	return f
+}
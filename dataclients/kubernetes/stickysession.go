@@ -0,0 +1,183 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/loadbalancer"
+	"github.com/zalando/skipper/predicates"
+)
+
+const skipperStickySessionAnnotationKey = "zalando.org/skipper-sticky-session"
+
+// stickySession is the configuration of the sticky sessions, defined by the
+// zalando.org/skipper-sticky-session annotation. The requests having the
+// session cookie or header are balanced with the consistentHash algorithm,
+// using the value of the cookie or the header as the hash key. The requests
+// without it, e.g. the first request of a session, are balanced with the
+// fallback algorithm.
+type stickySession struct {
+	Cookie        string  `json:"cookie"`
+	Header        string  `json:"header"`
+	BalanceFactor float64 `json:"balanceFactor"`
+	Fallback      string  `json:"fallback"`
+}
+
+// parse sticky session annotation
+func stickySessionAnnotation(m *definitions.Metadata, logger *log.Entry) *stickySession {
+	val, ok := m.Annotations[skipperStickySessionAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	var ss stickySession
+	if err := json.Unmarshal([]byte(val), &ss); err != nil {
+		logger.Errorf("Invalid %s annotation, failed to parse: %v", skipperStickySessionAnnotationKey, err)
+		return nil
+	}
+
+	if (ss.Cookie == "") == (ss.Header == "") {
+		logger.Errorf("Invalid %s annotation, either cookie or header is required: %s", skipperStickySessionAnnotationKey, val)
+		return nil
+	}
+
+	if ss.BalanceFactor != 0 && ss.BalanceFactor < 1 {
+		logger.Errorf("Invalid %s annotation, balance factor must be at least 1: %v", skipperStickySessionAnnotationKey, ss.BalanceFactor)
+		return nil
+	}
+
+	if ss.Fallback != "" {
+		if a, err := loadbalancer.AlgorithmFromString(ss.Fallback); err != nil || a == loadbalancer.ConsistentHash {
+			logger.Errorf("Invalid %s annotation, unsupported fallback algorithm: %s", skipperStickySessionAnnotationKey, ss.Fallback)
+			return nil
+		}
+	}
+
+	return &ss
+}
+
+func (ss *stickySession) predicate() *eskip.Predicate {
+	if ss.Cookie != "" {
+		return &eskip.Predicate{
+			Name: predicates.CookieName,
+			Args: []interface{}{ss.Cookie, "."},
+		}
+	}
+
+	return &eskip.Predicate{
+		Name: predicates.HeaderRegexpName,
+		Args: []interface{}{ss.Header, "."},
+	}
+}
+
+func (ss *stickySession) key() string {
+	if ss.Cookie != "" {
+		return fmt.Sprintf("${request.cookie.%s}", ss.Cookie)
+	}
+
+	return fmt.Sprintf("${request.header.%s}", ss.Header)
+}
+
+// route creates the sticky session route for a load balanced route of the
+// ingress, matching the requests with the session cookie or header, and sets
+// the fallback algorithm on the original route, used for the requests without
+// a session. The sessions mapped to the endpoints that were removed since the
+// previous conversion are balanced with the fallback algorithm, too, see
+// applyStickyFallbacks.
+func (ss *stickySession) route(r *eskip.Route) *eskip.Route {
+	sr := companionRoute(r, "sticky", 0, ss.predicate())
+
+	f := []*eskip.Filter{{
+		Name: filters.ConsistentHashKeyName,
+		Args: []interface{}{ss.key()},
+	}}
+
+	if ss.BalanceFactor > 0 {
+		f = append(f, &eskip.Filter{
+			Name: filters.ConsistentHashBalanceFactorName,
+			Args: []interface{}{ss.BalanceFactor},
+		})
+	}
+
+	if ss.Fallback != "" {
+		r.LBAlgorithm = ss.Fallback
+	}
+
+	if r.LBAlgorithm != loadbalancer.ConsistentHash.String() {
+		f = append(f, &eskip.Filter{
+			Name: filters.ConsistentHashFallbackName,
+			Args: []interface{}{r.LBAlgorithm},
+		})
+	}
+
+	sr.Filters = append(f, sr.Filters...)
+	sr.LBAlgorithm = loadbalancer.ConsistentHash.String()
+	return sr
+}
+
+// stickyEndpoints are the endpoints of a sticky session route, and the
+// endpoints removed by the last change of the endpoints.
+type stickyEndpoints struct {
+	current []string
+	removed []string
+}
+
+// removedEndpoints returns the endpoints of the previous endpoints, that are
+// not in the current ones.
+func removedEndpoints(previous, current []string) []string {
+	has := make(map[string]bool, len(current))
+	for _, ep := range current {
+		has[ep] = true
+	}
+
+	var removed []string
+	for _, ep := range previous {
+		if !has[ep] {
+			removed = append(removed, ep)
+		}
+	}
+
+	return removed
+}
+
+// applyStickyFallbacks adds the endpoints removed from the sticky session
+// routes to their consistentHashFallback filter, so that the sessions mapped
+// to the removed endpoints are balanced with the fallback algorithm, instead
+// of being moved to the neighbouring endpoints of the hash ring. The removed
+// endpoints are kept until the endpoints of the route change again. It returns
+// the endpoints of the sticky session routes, to be used by the next
+// conversion.
+func applyStickyFallbacks(r []*eskip.Route, previous map[string]stickyEndpoints) map[string]stickyEndpoints {
+	next := make(map[string]stickyEndpoints)
+	for _, ri := range r {
+		if ri.BackendType != eskip.LBBackend {
+			continue
+		}
+
+		for _, f := range ri.Filters {
+			if f.Name != filters.ConsistentHashFallbackName {
+				continue
+			}
+
+			se := stickyEndpoints{current: ri.LBEndpoints}
+			if p, ok := previous[ri.Id]; ok {
+				se.removed = removedEndpoints(p.current, se.current)
+				if len(se.removed) == 0 && len(removedEndpoints(se.current, p.current)) == 0 {
+					se.removed = p.removed
+				}
+			}
+
+			for _, ep := range se.removed {
+				f.Args = append(f.Args, ep)
+			}
+
+			next[ri.Id] = se
+		}
+	}
+
+	return next
+}
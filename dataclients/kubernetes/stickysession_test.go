@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+func TestStickySessionRemovedEndpoints(t *testing.T) {
+	ing := testIngress("foo", "qux", "", "", "", "", "", "", "", definitions.BackendPort{}, 1.0,
+		testRule("www.example.org", testPathRule("/", "bar", definitions.BackendPort{Value: "baz"})),
+	)
+	ing.Metadata.Annotations[skipperStickySessionAnnotationKey] = `{"cookie": "session", "fallback": "random"}`
+
+	api := newTestAPIWithEndpoints(t, &serviceList{Items: []*service{
+		testService("foo", "bar", "1.2.3.4", map[string]int{"baz": 8181}),
+	}}, &definitions.IngressList{Items: []*definitions.IngressItem{ing}}, &endpointList{
+		Items: testEndpoints("foo", "bar", "1.1.1", 3, map[string]int{"baz": 8181}),
+	}, &secretList{})
+	defer api.Close()
+
+	dc, err := New(Options{KubernetesURL: api.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer dc.Close()
+
+	load := func() (endpoints []string, fallback []interface{}) {
+		r, err := dc.LoadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, ri := range r {
+			if !strings.HasSuffix(ri.Id, "_sticky") {
+				continue
+			}
+
+			for _, f := range ri.Filters {
+				if f.Name == filters.ConsistentHashFallbackName {
+					return ri.LBEndpoints, f.Args
+				}
+			}
+		}
+
+		t.Fatal("sticky session route with fallback not found")
+		return nil, nil
+	}
+
+	initial, fallback := load()
+	if !reflect.DeepEqual(fallback, []interface{}{"random"}) {
+		t.Errorf("expected no removed endpoints initially, got: %v", fallback)
+	}
+
+	api.endpoints = &endpointList{Items: testEndpoints("foo", "bar", "1.1.1", 2, map[string]int{"baz": 8181})}
+	current, fallback := load()
+	if len(current) != 2 {
+		t.Fatalf("expected 2 endpoints, got: %v", current)
+	}
+
+	expected := []interface{}{"random", initial[2]}
+	if !reflect.DeepEqual(fallback, expected) {
+		t.Errorf("expected the removed endpoint in the fallback, got: %v, expected: %v", fallback, expected)
+	}
+
+	if _, fallback = load(); !reflect.DeepEqual(fallback, expected) {
+		t.Errorf("expected the removed endpoint to be kept while the endpoints don't change, got: %v", fallback)
+	}
+
+	api.endpoints = &endpointList{Items: testEndpoints("foo", "bar", "1.1.1", 3, map[string]int{"baz": 8181})}
+	if _, fallback = load(); !reflect.DeepEqual(fallback, []interface{}{"random"}) {
+		t.Errorf("expected no removed endpoints after the endpoint was added back, got: %v", fallback)
+	}
+}
+
+func TestStickySessionFallbackRoute(t *testing.T) {
+	r := &eskip.Route{
+		Id:          "foo",
+		BackendType: eskip.LBBackend,
+		LBAlgorithm: "roundRobin",
+		LBEndpoints: []string{"http://10.2.9.103:8080", "http://10.2.9.104:8080"},
+	}
+
+	sr := (&stickySession{Header: "X-Session"}).route(r)
+	if sr.LBAlgorithm != "consistentHash" || r.LBAlgorithm != "roundRobin" {
+		t.Errorf("unexpected algorithms, sticky: %s, fallback: %s", sr.LBAlgorithm, r.LBAlgorithm)
+	}
+
+	var hasKey, hasFallback bool
+	for _, f := range sr.Filters {
+		switch f.Name {
+		case filters.ConsistentHashKeyName:
+			hasKey = reflect.DeepEqual(f.Args, []interface{}{"${request.header.X-Session}"})
+		case filters.ConsistentHashFallbackName:
+			hasFallback = reflect.DeepEqual(f.Args, []interface{}{"roundRobin"})
+		}
+	}
+
+	if !hasKey || !hasFallback {
+		t.Errorf("expected the hash key and the fallback filters, got: %v", sr.Filters)
+	}
+
+	r.LBAlgorithm = "consistentHash"
+	for _, f := range (&stickySession{Header: "X-Session"}).route(r).Filters {
+		if f.Name == filters.ConsistentHashFallbackName {
+			t.Error("unexpected fallback for a consistentHash route")
+		}
+	}
+}
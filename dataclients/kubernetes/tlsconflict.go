@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"crypto/tls"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/secrets/certregistry"
+)
+
+// hostCert is a certificate declared for a host in the TLS section of an
+// ingress.
+type hostCert struct {
+	owner  definitions.ResourceID
+	secret definitions.ResourceID
+	cert   *tls.Certificate
+}
+
+// hostCerts collects the certificates declared for the hosts during a
+// conversion, to be registered once all the ingresses are processed.
+type hostCerts map[string][]hostCert
+
+func (hc hostCerts) add(host string, c hostCert) {
+	hc[host] = append(hc[host], c)
+}
+
+// configureHostCerts registers the collected certificates in the certificate
+// registry. When different secrets are declared for a host, the registered
+// certificate is selected by the TLS conflict policy.
+func (ing *ingress) configureHostCerts(hc hostCerts, r *certregistry.CertRegistry) {
+	hosts := make([]string, 0, len(hc))
+	for host := range hc {
+		hosts = append(hosts, host)
+	}
+
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		certs := hc[host]
+		sort.SliceStable(certs, func(i, j int) bool {
+			if certs[i].owner != certs[j].owner {
+				return resourceIDLess(certs[i].owner, certs[j].owner)
+			}
+
+			return resourceIDLess(certs[i].secret, certs[j].secret)
+		})
+
+		if conflictingSecrets(certs) {
+			first := certs[0]
+			switch ing.tlsConflictPolicy {
+			case TLSConflictFirstWins:
+				log.Warnf(
+					"Conflicting TLS secrets for host %s, using secret %s of ingress %s/%s",
+					host, first.secret.Name, first.owner.Namespace, first.owner.Name,
+				)
+
+				certs = certs[:1]
+			case TLSConflictError:
+				log.Errorf("Conflicting TLS secrets for host %s, no certificate is registered", host)
+				continue
+			default:
+				log.Infof("Conflicting TLS secrets for host %s, using the newest certificate", host)
+			}
+		}
+
+		for _, c := range certs {
+			if err := r.ConfigureCertificate(host, c.cert); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+func conflictingSecrets(certs []hostCert) bool {
+	for _, c := range certs[1:] {
+		if c.secret != certs[0].secret {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -40,3 +41,78 @@ func TestUpdateOnlyChangedRoutes(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteGracePeriod(t *testing.T) {
+	api := newTestAPIWithEndpoints(t, testServices(), &definitions.IngressList{}, testEndpointList(), &secretList{})
+	defer api.Close()
+
+	t.Run("route reappears within the grace period", func(t *testing.T) {
+		api.ingresses.Items = testIngresses()
+
+		k, err := New(Options{
+			KubernetesURL:     api.server.URL,
+			DeleteGracePeriod: time.Hour,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer k.Close()
+
+		if _, err := k.LoadAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		api.ingresses.Items = testIngresses()[1:]
+		update, del, err := k.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, update, "unexpected update")
+		assert.Empty(t, del, "unexpected delete before the grace period")
+
+		api.ingresses.Items = testIngresses()
+		update, del, err = k.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, update, "unexpected update")
+		assert.Empty(t, del, "unexpected delete after the route reappeared")
+	})
+
+	t.Run("route deleted after the grace period", func(t *testing.T) {
+		api.ingresses.Items = testIngresses()
+
+		k, err := New(Options{
+			KubernetesURL:     api.server.URL,
+			DeleteGracePeriod: time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer k.Close()
+
+		if _, err := k.LoadAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		api.ingresses.Items = testIngresses()[1:]
+		_, del, err := k.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, del, "unexpected delete before the grace period")
+
+		time.Sleep(2 * time.Millisecond)
+		_, del, err = k.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NotEmpty(t, del, "expected delete after the grace period")
+	})
+}
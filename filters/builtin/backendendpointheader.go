@@ -0,0 +1,44 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type backendEndpointHeader struct {
+	name string
+}
+
+// NewBackendEndpointHeader creates a filter specification for the
+// backendEndpointHeader filter, that sets a response header to the address
+// of the endpoint selected by the load balancer, e.g. for debugging the load
+// balancing. The header is not set for the other types of backends.
+//
+// Example:
+//
+//	r: * -> backendEndpointHeader("X-Backend-Endpoint") -> <"http://10.2.9.103:8080", "http://10.2.9.104:8080">;
+func NewBackendEndpointHeader() filters.Spec {
+	return &backendEndpointHeader{}
+}
+
+func (*backendEndpointHeader) Name() string { return filters.BackendEndpointHeaderName }
+
+func (*backendEndpointHeader) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	name, ok := args[0].(string)
+	if !ok || name == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &backendEndpointHeader{name: name}, nil
+}
+
+func (*backendEndpointHeader) Request(filters.FilterContext) {}
+
+func (f *backendEndpointHeader) Response(ctx filters.FilterContext) {
+	if endpoint, ok := ctx.StateBag()[filters.BackendEndpoint].(string); ok {
+		ctx.Response().Header.Set(f.name, endpoint)
+	}
+}
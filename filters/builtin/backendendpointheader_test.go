@@ -0,0 +1,47 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestBackendEndpointHeader(t *testing.T) {
+	spec := NewBackendEndpointHeader()
+	if spec.Name() != filters.BackendEndpointHeaderName {
+		t.Error("wrong name")
+	}
+
+	for _, args := range [][]interface{}{nil, {""}, {42.0}, {"X-Foo", "X-Bar"}} {
+		if _, err := spec.CreateFilter(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+
+	f, err := spec.CreateFilter([]interface{}{"X-Backend-Endpoint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{
+		FResponse: &http.Response{Header: make(http.Header)},
+		FStateBag: map[string]interface{}{filters.BackendEndpoint: "10.2.9.103:8080"},
+	}
+
+	f.Response(c)
+	if h := c.FResponse.Header.Get("X-Backend-Endpoint"); h != "10.2.9.103:8080" {
+		t.Errorf("wrong backend endpoint header: %q", h)
+	}
+
+	c = &filtertest.Context{
+		FResponse: &http.Response{Header: make(http.Header)},
+		FStateBag: make(map[string]interface{}),
+	}
+
+	f.Response(c)
+	if _, ok := c.FResponse.Header["X-Backend-Endpoint"]; ok {
+		t.Error("unexpected backend endpoint header without a selected endpoint")
+	}
+}
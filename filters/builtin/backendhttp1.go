@@ -0,0 +1,35 @@
+package builtin
+
+import "github.com/zalando/skipper/filters"
+
+type backendHTTP1 struct{}
+
+// NewBackendHTTP1 creates a filter specification for the backendHTTP1
+// filter, that forces HTTP/1.1 to the backend of the route, for the legacy
+// backends breaking under HTTP/2. The proxy sends the backend requests with
+// HTTP/1.1, but when the protocol upgrades are enabled, the clients could
+// upgrade the backend connections to HTTP/2 over cleartext (h2c). This
+// filter prevents these upgrades.
+//
+// Example:
+//
+//	r: * -> backendHTTP1() -> "http://10.2.0.1:8080";
+func NewBackendHTTP1() filters.Spec {
+	return &backendHTTP1{}
+}
+
+func (*backendHTTP1) Name() string { return filters.BackendHTTP1Name }
+
+func (*backendHTTP1) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &backendHTTP1{}, nil
+}
+
+func (*backendHTTP1) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendHTTP1] = true
+}
+
+func (*backendHTTP1) Response(filters.FilterContext) {}
@@ -0,0 +1,32 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestBackendHTTP1(t *testing.T) {
+	spec := NewBackendHTTP1()
+	if spec.Name() != filters.BackendHTTP1Name {
+		t.Error("wrong name")
+	}
+
+	if _, err := spec.CreateFilter([]interface{}{"foo"}); err == nil {
+		t.Error("expected error for arguments")
+	}
+
+	f, err := spec.CreateFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{FRequest: &http.Request{}, FStateBag: make(map[string]interface{})}
+	f.Request(c)
+
+	if c.FStateBag[filters.BackendHTTP1] != true {
+		t.Error("failed to force HTTP/1.1")
+	}
+}
@@ -149,16 +149,27 @@ func MakeRegistry() filters.Registry {
 		NewInlineContent(),
 		NewInlineContentIfStatus(),
 		flowid.New(),
+		flowid.NewRequestId(),
 		xforward.New(),
 		xforward.NewFirst(),
 		PreserveHost(),
 		NewSetFastCgiFilename(),
 		NewStatus(),
+		NewMaxRequestBody(),
+		NewMaxResponseBody(),
+		NewBackendEndpointHeader(),
 		NewCompress(),
 		NewDecompress(),
+		NewDecompressRequest(),
 		NewHeaderToQuery(),
 		NewQueryToHeader(),
 		NewBackendTimeout(),
+		NewBackendSNI(),
+		NewConnectTimeout(),
+		NewRetryBudget(),
+		NewDisableRetry(),
+		NewBackendHTTP1(),
+		NewFlushInterval(),
 		NewSetDynamicBackendHostFromHeader(),
 		NewSetDynamicBackendSchemeFromHeader(),
 		NewSetDynamicBackendUrlFromHeader(),
@@ -193,6 +204,7 @@ func MakeRegistry() filters.Registry {
 		cookie.NewRequestCookie(),
 		cookie.NewResponseCookie(),
 		cookie.NewJSCookie(),
+		cookie.NewSameSite(),
 		circuit.NewConsecutiveBreaker(),
 		circuit.NewRateBreaker(),
 		circuit.NewDisableBreaker(),
@@ -210,12 +222,14 @@ func MakeRegistry() filters.Registry {
 		auth.NewForwardTokenField(),
 		scheduler.NewLIFO(),
 		scheduler.NewLIFOGroup(),
+		scheduler.NewClientConcurrency(),
 		rfc.NewPath(),
 		rfc.NewHost(),
 		fadein.NewFadeIn(),
 		fadein.NewEndpointCreated(),
 		consistenthash.NewConsistentHashKey(),
 		consistenthash.NewConsistentHashBalanceFactor(),
+		consistenthash.NewConsistentHashFallback(),
 	} {
 		r.Register(s)
 	}
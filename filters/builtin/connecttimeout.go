@@ -0,0 +1,59 @@
+package builtin
+
+import (
+	"time"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type connectTimeout struct {
+	timeout time.Duration
+}
+
+// NewConnectTimeout creates a filter specification for the connectTimeout
+// filter, that sets the timeout of establishing the connection to the
+// backend. Unlike the backendTimeout filter, it doesn't limit the duration
+// of the backend request on an established connection. A longer timeout than
+// the default dial timeout of the proxy has no effect.
+//
+// Example:
+//
+//	r: * -> connectTimeout("2s") -> "https://www.example.org";
+func NewConnectTimeout() filters.Spec {
+	return &connectTimeout{}
+}
+
+func (*connectTimeout) Name() string { return filters.ConnectTimeoutName }
+
+func (*connectTimeout) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var f connectTimeout
+	switch v := args[0].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		f.timeout = d
+	case time.Duration:
+		f.timeout = v
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if f.timeout <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &f, nil
+}
+
+func (f *connectTimeout) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.BackendConnectTimeout] = f.timeout
+}
+
+func (*connectTimeout) Response(filters.FilterContext) {}
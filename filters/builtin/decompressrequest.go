@@ -0,0 +1,54 @@
+package builtin
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+type decompressRequest struct{}
+
+// NewDecompressRequest creates a filter specification for the
+// decompressRequest() filter. The filter decompresses the request body, if
+// it was compressed with any of deflate, gzip or br, for the backends that
+// cannot handle compressed requests.
+//
+// When the encoding is not supported, the request is forwarded unchanged. When
+// the decompression cannot be initialized, the request is rejected with 400
+// Bad Request.
+//
+// The filter does not need any parameters.
+func NewDecompressRequest() filters.Spec {
+	return decompressRequest{}
+}
+
+func (d decompressRequest) Name() string { return filters.DecompressRequestName }
+
+func (d decompressRequest) CreateFilter([]interface{}) (filters.Filter, error) {
+	return d, nil
+}
+
+func (d decompressRequest) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+
+	encs := getEncodings(req.Header.Get("Content-Encoding"))
+	if len(encs) == 0 || !encodingsSupported(encs) || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	b, err := newDecodedBody(req.Body, encs)
+	if err != nil {
+		req.Body.Close()
+		log.Errorf("Error while initializing request decompression: %v", err)
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	req.Body = b
+}
+
+func (d decompressRequest) Response(filters.FilterContext) {}
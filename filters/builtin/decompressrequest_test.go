@@ -0,0 +1,103 @@
+package builtin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestDecompressRequest(t *testing.T) {
+	const content = "Hello, world!"
+
+	gzipped := func() []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write([]byte(content))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	for _, tt := range []struct {
+		msg              string
+		encoding         string
+		body             []byte
+		expectedStatus   int
+		expectedEncoding string
+		expectedBody     string
+	}{{
+		msg:            "not compressed",
+		body:           []byte(content),
+		expectedStatus: http.StatusOK,
+		expectedBody:   content,
+	}, {
+		msg:            "gzip",
+		encoding:       "gzip",
+		body:           gzipped(),
+		expectedStatus: http.StatusOK,
+		expectedBody:   content,
+	}, {
+		msg:              "unsupported encoding",
+		encoding:         "zstd",
+		body:             []byte(content),
+		expectedStatus:   http.StatusOK,
+		expectedEncoding: "zstd",
+		expectedBody:     content,
+	}, {
+		msg:            "invalid gzip",
+		encoding:       "gzip",
+		body:           []byte(content),
+		expectedStatus: http.StatusBadRequest,
+	}} {
+		t.Run(tt.msg, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+
+				if string(b) != tt.expectedBody {
+					t.Errorf("expected body %q, got: %q", tt.expectedBody, b)
+				}
+
+				if e := r.Header.Get("Content-Encoding"); e != tt.expectedEncoding {
+					t.Errorf("expected encoding %q, got: %q", tt.expectedEncoding, e)
+				}
+			}))
+			defer backend.Close()
+
+			fr := make(filters.Registry)
+			fr.Register(NewDecompressRequest())
+			p := proxytest.New(fr, &eskip.Route{
+				Filters: []*eskip.Filter{{Name: filters.DecompressRequestName}},
+				Backend: backend.URL,
+			})
+			defer p.Close()
+
+			req, err := http.NewRequest("POST", p.URL, bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got: %d", tt.expectedStatus, rsp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package builtin
+
+import "github.com/zalando/skipper/filters"
+
+type disableRetry struct{}
+
+// NewDisableRetry creates a filter specification for the disableRetry
+// filter, that disables the retries of the failed backend requests of the
+// route, e.g. on the routes of the non-idempotent methods.
+//
+// Example:
+//
+//	r: Methods("POST", "PATCH") -> disableRetry() -> <"http://10.2.0.1", "http://10.2.0.2">;
+func NewDisableRetry() filters.Spec {
+	return &disableRetry{}
+}
+
+func (*disableRetry) Name() string { return filters.DisableRetryName }
+
+func (*disableRetry) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &disableRetry{}, nil
+}
+
+func (*disableRetry) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendDisableRetry] = true
+}
+
+func (*disableRetry) Response(filters.FilterContext) {}
@@ -0,0 +1,32 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestDisableRetry(t *testing.T) {
+	spec := NewDisableRetry()
+	if spec.Name() != filters.DisableRetryName {
+		t.Error("wrong name")
+	}
+
+	if _, err := spec.CreateFilter([]interface{}{"foo"}); err == nil {
+		t.Error("expected error for arguments")
+	}
+
+	f, err := spec.CreateFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{FRequest: &http.Request{}, FStateBag: make(map[string]interface{})}
+	f.Request(c)
+
+	if c.FStateBag[filters.BackendDisableRetry] != true {
+		t.Error("failed to disable the retries")
+	}
+}
@@ -0,0 +1,59 @@
+package builtin
+
+import (
+	"time"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type flushInterval struct {
+	interval time.Duration
+}
+
+// NewFlushInterval creates a filter specification for the flushInterval
+// filter, that sets how often the proxy flushes the response body, and the
+// data of the upgraded connections, to the client. By default, the response
+// body is flushed after every write, e.g. for server-sent events. Larger
+// intervals reduce the number of flushes of the frequently writing backends.
+//
+// Example:
+//
+//	r: * -> flushInterval("100ms") -> "https://www.example.org";
+func NewFlushInterval() filters.Spec {
+	return &flushInterval{}
+}
+
+func (*flushInterval) Name() string { return filters.FlushIntervalName }
+
+func (*flushInterval) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var f flushInterval
+	switch v := args[0].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		f.interval = d
+	case time.Duration:
+		f.interval = v
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if f.interval <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &f, nil
+}
+
+func (f *flushInterval) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.FlushInterval] = f.interval
+}
+
+func (*flushInterval) Response(filters.FilterContext) {}
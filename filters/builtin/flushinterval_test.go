@@ -0,0 +1,35 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestFlushInterval(t *testing.T) {
+	spec := NewFlushInterval()
+	if spec.Name() != filters.FlushIntervalName {
+		t.Error("wrong name")
+	}
+
+	for _, args := range [][]interface{}{nil, {"foo"}, {"0s"}, {"-1s"}, {42.0}, {"1s", "2s"}} {
+		if _, err := spec.CreateFilter(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+
+	f, err := spec.CreateFilter([]interface{}{"100ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{FRequest: &http.Request{}, FStateBag: make(map[string]interface{})}
+	f.Request(c)
+
+	if c.FStateBag[filters.FlushInterval] != 100*time.Millisecond {
+		t.Errorf("wrong flush interval: %v", c.FStateBag[filters.FlushInterval])
+	}
+}
@@ -0,0 +1,68 @@
+package builtin
+
+import (
+	"net/http"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type maxRequestBodySpec struct{}
+
+type maxRequestBody struct {
+	limit int64
+}
+
+// NewMaxRequestBody creates a filter specification whose instances reject
+// the requests with a body larger than the configured number of bytes,
+// responding with 413 Request Entity Too Large. The body of the requests
+// with an unknown content length is streamed to the backend, and the request
+// fails with 413, when the body exceeds the limit.
+func NewMaxRequestBody() filters.Spec { return &maxRequestBodySpec{} }
+
+func (*maxRequestBodySpec) Name() string { return filters.MaxRequestBodyName }
+
+func (*maxRequestBodySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var limit int64
+	switch v := args[0].(type) {
+	case int:
+		limit = int64(v)
+	case float64:
+		limit = int64(v)
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if limit <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &maxRequestBody{limit: limit}, nil
+}
+
+func serveStatus(ctx filters.FilterContext, code int) {
+	ctx.Serve(&http.Response{
+		StatusCode: code,
+		Header:     http.Header{"Content-Length": []string{"0"}},
+		Body:       http.NoBody,
+	})
+}
+
+func (f *maxRequestBody) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+	if r.ContentLength > f.limit {
+		serveStatus(ctx, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if r.ContentLength >= 0 || r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	r.Body = &limitedBody{body: r.Body, remaining: f.limit, err: filters.ErrRequestBodyTooLarge}
+}
+
+func (*maxRequestBody) Response(filters.FilterContext) {}
@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestMaxRequestBodyArgs(t *testing.T) {
+	spec := NewMaxRequestBody()
+	for _, args := range [][]interface{}{
+		nil,
+		{"5MB"},
+		{float64(0)},
+		{float64(-1)},
+		{float64(1), float64(2)},
+	} {
+		if _, err := spec.CreateFilter(args); err != filters.ErrInvalidFilterParameters {
+			t.Errorf("expected an invalid parameters error for %v, got: %v", args, err)
+		}
+	}
+}
+
+func TestMaxRequestBody(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewMaxRequestBody())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.MaxRequestBodyName, Args: []interface{}{float64(5)}}},
+		Backend: backend.URL,
+	})
+	defer pr.Close()
+
+	for _, test := range []struct {
+		title    string
+		body     string
+		chunked  bool
+		expected int
+	}{{
+		title:    "no body",
+		expected: http.StatusOK,
+	}, {
+		title:    "within limit",
+		body:     "foo",
+		expected: http.StatusOK,
+	}, {
+		title:    "exactly the limit",
+		body:     "fooba",
+		expected: http.StatusOK,
+	}, {
+		title:    "over the limit",
+		body:     "foobar",
+		expected: http.StatusRequestEntityTooLarge,
+	}, {
+		title:    "chunked, within limit",
+		body:     "foo",
+		chunked:  true,
+		expected: http.StatusOK,
+	}, {
+		title:    "chunked, over the limit",
+		body:     "foobar",
+		chunked:  true,
+		expected: http.StatusRequestEntityTooLarge,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(test.body)
+			if test.chunked {
+				// hide the length of the body from the client
+				body = io.MultiReader(body)
+			}
+
+			rsp, err := http.Post(pr.URL, "text/plain", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.expected {
+				t.Fatalf("unexpected status code: %d, expected: %d", rsp.StatusCode, test.expected)
+			}
+
+			if rsp.StatusCode != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Errorf("unexpected body received by the backend: %q, expected: %q", b, test.body)
+			}
+		})
+	}
+}
+
+func TestMaxRequestBodyStreaming(t *testing.T) {
+	received := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := make([]byte, 3)
+		if _, err := io.ReadFull(r.Body, b); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		close(received)
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewMaxRequestBody())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.MaxRequestBodyName, Args: []interface{}{float64(1 << 20)}}},
+		Backend: backend.URL,
+	})
+	defer pr.Close()
+
+	body, w := io.Pipe()
+	go func() {
+		w.Write([]byte("foo"))
+
+		// the rest of the body is sent only after the backend received the
+		// first part, which fails when the proxy buffers the body
+		select {
+		case <-received:
+			w.Close()
+		case <-time.After(3 * time.Second):
+			w.CloseWithError(errors.New("timeout"))
+		}
+	}()
+
+	rsp, err := http.Post(pr.URL, "text/plain", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", rsp.StatusCode)
+	}
+}
@@ -0,0 +1,97 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type maxResponseBodySpec struct{}
+
+type maxResponseBody struct {
+	limit int64
+}
+
+// limitedBody returns the error, when reading more than the remaining bytes
+// of the body.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	err       error
+}
+
+// NewMaxResponseBody creates a filter specification whose instances limit
+// the size of the response body to the configured number of bytes. Responses
+// with a larger content length are replaced by 502 Bad Gateway, while the
+// responses with an unknown content length are truncated at the limit.
+func NewMaxResponseBody() filters.Spec { return &maxResponseBodySpec{} }
+
+func (*maxResponseBodySpec) Name() string { return filters.MaxResponseBodyName }
+
+func (*maxResponseBodySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var limit int64
+	switch v := args[0].(type) {
+	case int:
+		limit = int64(v)
+	case float64:
+		limit = int64(v)
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if limit <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &maxResponseBody{limit: limit}, nil
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.err
+	}
+
+	// reading one byte more than the limit tells whether it was exceeded
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), b.err
+	}
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+func (*maxResponseBody) Request(filters.FilterContext) {}
+
+func (f *maxResponseBody) Response(ctx filters.FilterContext) {
+	rsp := ctx.Response()
+	if rsp.Body == nil || rsp.Body == http.NoBody {
+		return
+	}
+
+	if rsp.ContentLength > f.limit {
+		rsp.Body.Close()
+		rsp.StatusCode = http.StatusBadGateway
+		rsp.Header = http.Header{"Content-Length": []string{"0"}}
+		rsp.ContentLength = 0
+		rsp.Body = http.NoBody
+		return
+	}
+
+	if rsp.ContentLength < 0 {
+		rsp.Body = &limitedBody{body: rsp.Body, remaining: f.limit, err: filters.ErrResponseBodyTooLarge}
+	}
+}
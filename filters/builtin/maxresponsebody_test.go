@@ -0,0 +1,111 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestMaxResponseBodyArgs(t *testing.T) {
+	spec := NewMaxResponseBody()
+	for _, args := range [][]interface{}{
+		nil,
+		{"50MB"},
+		{float64(0)},
+		{float64(-1)},
+		{float64(1), float64(2)},
+	} {
+		if _, err := spec.CreateFilter(args); err != filters.ErrInvalidFilterParameters {
+			t.Errorf("expected an invalid parameters error for %v, got: %v", args, err)
+		}
+	}
+}
+
+func TestMaxResponseBody(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.URL.Query().Get("body")
+		if r.URL.Query().Get("chunked") != "" {
+			// flushing before writing hides the length of the body from the proxy
+			w.(http.Flusher).Flush()
+		}
+
+		w.Write([]byte(body))
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewMaxResponseBody())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.MaxResponseBodyName, Args: []interface{}{float64(5)}}},
+		Backend: backend.URL,
+	})
+	defer pr.Close()
+
+	for _, test := range []struct {
+		title        string
+		body         string
+		chunked      bool
+		expected     int
+		expectedBody string
+	}{{
+		title:    "no body",
+		expected: http.StatusOK,
+	}, {
+		title:        "within limit",
+		body:         "foo",
+		expected:     http.StatusOK,
+		expectedBody: "foo",
+	}, {
+		title:        "exactly the limit",
+		body:         "fooba",
+		expected:     http.StatusOK,
+		expectedBody: "fooba",
+	}, {
+		title:    "over the limit",
+		body:     "foobar",
+		expected: http.StatusBadGateway,
+	}, {
+		title:        "chunked, within limit",
+		body:         "foo",
+		chunked:      true,
+		expected:     http.StatusOK,
+		expectedBody: "foo",
+	}, {
+		title:        "chunked, over the limit",
+		body:         "foobar",
+		chunked:      true,
+		expected:     http.StatusOK,
+		expectedBody: "fooba",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			u := pr.URL + "?body=" + test.body
+			if test.chunked {
+				u += "&chunked=true"
+			}
+
+			rsp, err := http.Get(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.expected {
+				t.Fatalf("unexpected status code: %d, expected: %d", rsp.StatusCode, test.expected)
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.expectedBody {
+				t.Errorf("unexpected response body: %q, expected: %q", b, test.expectedBody)
+			}
+		})
+	}
+}
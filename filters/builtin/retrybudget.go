@@ -0,0 +1,103 @@
+package builtin
+
+import (
+	"sync/atomic"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/scheduler"
+)
+
+type retryBudgetSpec struct{}
+
+type retryBudget struct {
+	ratio float64
+	min   int64
+
+	// accessed atomically
+	active  int64
+	retries int64
+}
+
+// NewRetryBudget creates a filter specification for the retryBudget filter,
+// that limits the retries of the failed backend requests of the route,
+// preventing retry storms when the backend is unavailable. The concurrent
+// retries are limited to the given ratio of the active requests of the
+// route, but at least the given minimum number of concurrent retries is
+// allowed.
+//
+// Example:
+//
+//	r: * -> retryBudget(0.1, 3) -> <"http://10.2.0.1", "http://10.2.0.2">;
+func NewRetryBudget() filters.Spec {
+	return &retryBudgetSpec{}
+}
+
+func (*retryBudgetSpec) Name() string { return filters.RetryBudgetName }
+
+func (*retryBudgetSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	ratio, ok := args[0].(float64)
+	if !ok || ratio < 0 || ratio > 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var min int64
+	switch v := args[1].(type) {
+	case int:
+		min = int64(v)
+	case float64:
+		min = int64(v)
+		if float64(min) != v {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if min < 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &retryBudget{ratio: ratio, min: min}, nil
+}
+
+// Request counts the request as active, and passes the budget to the proxy.
+// The request is released the same way as the ones of the lifo filters, so
+// that it happens even when the response filters are not executed.
+func (f *retryBudget) Request(ctx filters.FilterContext) {
+	atomic.AddInt64(&f.active, 1)
+	done := func() { atomic.AddInt64(&f.active, -1) }
+
+	pending, _ := ctx.StateBag()[scheduler.LIFOKey].([]func())
+	ctx.StateBag()[scheduler.LIFOKey] = append(pending, done)
+
+	// allows overwrite
+	ctx.StateBag()[filters.BackendRetryBudget] = f.retry
+}
+
+// Response releases the request.
+func (*retryBudget) Response(ctx filters.FilterContext) {
+	pending, _ := ctx.StateBag()[scheduler.LIFOKey].([]func())
+	last := len(pending) - 1
+	if last < 0 {
+		return
+	}
+
+	pending[last]()
+	ctx.StateBag()[scheduler.LIFOKey] = pending[:last]
+}
+
+// retry reserves a retry from the budget, and returns the function releasing
+// it. It returns false, when the budget is exhausted.
+func (f *retryBudget) retry() (func(), bool) {
+	retries := atomic.AddInt64(&f.retries, 1)
+	if retries > f.min && float64(retries) > f.ratio*float64(atomic.LoadInt64(&f.active)) {
+		atomic.AddInt64(&f.retries, -1)
+		return nil, false
+	}
+
+	return func() { atomic.AddInt64(&f.retries, -1) }, true
+}
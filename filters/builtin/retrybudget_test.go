@@ -0,0 +1,88 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRetryBudgetArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{0.1},
+		{"0.1", 3},
+		{-0.1, 3},
+		{1.1, 3},
+		{0.1, -1},
+		{0.1, 1.5},
+		{0.1, "3"},
+		{0.1, 3, 4},
+	} {
+		if _, err := NewRetryBudget().CreateFilter(args); err == nil {
+			t.Errorf("expected error for args: %v", args)
+		}
+	}
+
+	for _, args := range [][]interface{}{
+		{0.0, 0},
+		{0.1, 3},
+		{1.0, float64(3)},
+	} {
+		if _, err := NewRetryBudget().CreateFilter(args); err != nil {
+			t.Errorf("unexpected error for args %v: %v", args, err)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	f, err := NewRetryBudget().CreateFilter([]interface{}{0.5, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// four active requests allow two concurrent retries
+	contexts := make([]*filtertest.Context, 4)
+	for i := range contexts {
+		contexts[i] = &filtertest.Context{FStateBag: make(map[string]interface{})}
+		f.Request(contexts[i])
+	}
+
+	retry, ok := contexts[0].StateBag()[filters.BackendRetryBudget].(func() (func(), bool))
+	if !ok {
+		t.Fatal("retry budget not set")
+	}
+
+	release1, ok := retry()
+	if !ok {
+		t.Fatal("expected the first retry to be allowed")
+	}
+
+	release2, ok := retry()
+	if !ok {
+		t.Fatal("expected the second retry to be allowed")
+	}
+
+	if _, ok := retry(); ok {
+		t.Fatal("expected the third retry to exceed the budget")
+	}
+
+	release1()
+	release2()
+
+	// with one active request, only the minimum is allowed
+	for _, ctx := range contexts[1:] {
+		f.Response(ctx)
+	}
+
+	release, ok := retry()
+	if !ok {
+		t.Fatal("expected the minimum retries to be allowed")
+	}
+
+	if _, ok := retry(); ok {
+		t.Fatal("expected the retry above the minimum to exceed the budget")
+	}
+
+	release()
+}
@@ -0,0 +1,42 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type backendSNI struct {
+	serverName string
+}
+
+// NewBackendSNI creates a filter specification for the backendSNI filter,
+// that sets the server name presented in the TLS handshake with the https
+// backends, when it needs to differ from the host of the backend.
+//
+// Example:
+//
+//	r: * -> backendSNI("internal.svc") -> "https://10.2.9.103";
+func NewBackendSNI() filters.Spec {
+	return &backendSNI{}
+}
+
+func (*backendSNI) Name() string { return filters.BackendSNIName }
+
+func (*backendSNI) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	serverName, ok := args[0].(string)
+	if !ok || serverName == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &backendSNI{serverName: serverName}, nil
+}
+
+func (s *backendSNI) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.BackendSNI] = s.serverName
+}
+
+func (*backendSNI) Response(filters.FilterContext) {}
@@ -0,0 +1,34 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestBackendSNI(t *testing.T) {
+	spec := NewBackendSNI()
+	if spec.Name() != filters.BackendSNIName {
+		t.Error("wrong name")
+	}
+
+	for _, args := range [][]interface{}{nil, {""}, {42.0}, {"a", "b"}} {
+		if _, err := spec.CreateFilter(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+
+	f, err := spec.CreateFilter([]interface{}{"internal.svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{FRequest: &http.Request{}, FStateBag: make(map[string]interface{})}
+	f.Request(c)
+
+	if c.FStateBag[filters.BackendSNI] != "internal.svc" {
+		t.Errorf("wrong server name: %v", c.FStateBag[filters.BackendSNI])
+	}
+}
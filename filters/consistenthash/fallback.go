@@ -0,0 +1,54 @@
+package consistenthash
+
+import (
+	"fmt"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/loadbalancer"
+)
+
+type consistentHashFallback struct {
+	fallback *loadbalancer.Fallback
+}
+
+// NewConsistentHashFallback creates a filter Spec, whose instances
+// set the fallback algorithm used by the `consistentHash` algorithm for
+// the keys that were mapped to the removed endpoints
+func NewConsistentHashFallback() filters.Spec { return &consistentHashFallback{} }
+func (*consistentHashFallback) Name() string {
+	return filters.ConsistentHashFallbackName
+}
+
+func (*consistentHashFallback) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	name, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	a, err := loadbalancer.AlgorithmFromString(name)
+	if err != nil || a == loadbalancer.ConsistentHash {
+		return nil, fmt.Errorf("invalid consistentHashFallback algorithm: %s", name)
+	}
+
+	removed := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		endpoint, ok := arg.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		removed = append(removed, endpoint)
+	}
+
+	return &consistentHashFallback{loadbalancer.NewFallback(a, removed)}, nil
+}
+
+func (c *consistentHashFallback) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[loadbalancer.ConsistentHashFallback] = c.fallback
+}
+
+func (*consistentHashFallback) Response(filters.FilterContext) {}
@@ -0,0 +1,41 @@
+package consistenthash
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/loadbalancer"
+)
+
+func TestConsistentHashFallback(t *testing.T) {
+	spec := NewConsistentHashFallback()
+	if spec.Name() != "consistentHashFallback" {
+		t.Error("wrong name")
+	}
+
+	c := &filtertest.Context{
+		FStateBag: make(map[string]interface{}),
+	}
+
+	f, err := spec.CreateFilter([]interface{}{"random", "http://10.2.9.105:8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Request(c)
+
+	if _, ok := c.FStateBag[loadbalancer.ConsistentHashFallback].(*loadbalancer.Fallback); !ok {
+		t.Error("Failed to set fallback via filter")
+	}
+
+	for _, args := range [][]interface{}{
+		nil,
+		{"consistentHash"},
+		{"unknown"},
+		{"random", 42},
+	} {
+		if _, err := spec.CreateFilter(args); err == nil {
+			t.Errorf("Expected an error for the arguments: %v", args)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cookie
+
+import (
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// SameSiteValues contains the accepted values of the SameSite cookie
+// attribute.
+var SameSiteValues = []string{"Strict", "Lax", "None"}
+
+type sameSiteSpec struct{}
+
+type sameSiteFilter struct {
+	value string
+}
+
+// NewSameSite creates a filter spec for setting the SameSite attribute of
+// the cookies in the Set-Cookie headers of the responses. An existing
+// SameSite attribute is replaced by the configured one.
+// Name: cookieSameSite
+//
+// Example:
+//
+//	cookieSameSite("Strict")
+func NewSameSite() filters.Spec { return sameSiteSpec{} }
+
+// ParseSameSite returns the canonical form of a SameSite attribute value,
+// and false, when the value is not one of SameSiteValues.
+func ParseSameSite(value string) (string, bool) {
+	for _, v := range SameSiteValues {
+		if strings.EqualFold(v, value) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+func (sameSiteSpec) Name() string { return filters.CookieSameSiteName }
+
+func (sameSiteSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	value, ok := ParseSameSite(s)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &sameSiteFilter{value: value}, nil
+}
+
+func (f *sameSiteFilter) Request(filters.FilterContext) {}
+
+func (f *sameSiteFilter) Response(ctx filters.FilterContext) {
+	h := ctx.Response().Header
+	cookies := h.Values(SetCookieHttpHeader)
+	if len(cookies) == 0 {
+		return
+	}
+
+	updated := make([]string, len(cookies))
+	for i, c := range cookies {
+		updated[i] = f.setSameSite(c)
+	}
+
+	h[SetCookieHttpHeader] = updated
+}
+
+// setSameSite drops any existing SameSite attribute of the cookie, and
+// appends the configured one.
+func (f *sameSiteFilter) setSameSite(cookie string) string {
+	parts := strings.Split(cookie, ";")
+	attrs := []string{parts[0]}
+	for _, p := range parts[1:] {
+		key := strings.TrimSpace(p)
+		if i := strings.Index(key, "="); i >= 0 {
+			key = key[:i]
+		}
+
+		if strings.EqualFold(strings.TrimSpace(key), "SameSite") {
+			continue
+		}
+
+		attrs = append(attrs, p)
+	}
+
+	return strings.Join(attrs, ";") + "; SameSite=" + f.value
+}
@@ -0,0 +1,121 @@
+package cookie
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestSameSiteCreateFilter(t *testing.T) {
+	for _, ti := range []struct {
+		msg   string
+		args  []interface{}
+		value string
+		err   bool
+	}{{
+		msg: "no arguments",
+		err: true,
+	}, {
+		msg:  "too many arguments",
+		args: []interface{}{"Strict", "Lax"},
+		err:  true,
+	}, {
+		msg:  "not a string",
+		args: []interface{}{42.0},
+		err:  true,
+	}, {
+		msg:  "invalid value",
+		args: []interface{}{"Sometimes"},
+		err:  true,
+	}, {
+		msg:   "strict",
+		args:  []interface{}{"Strict"},
+		value: "Strict",
+	}, {
+		msg:   "lax, case insensitive",
+		args:  []interface{}{"lax"},
+		value: "Lax",
+	}, {
+		msg:   "none",
+		args:  []interface{}{"None"},
+		value: "None",
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewSameSite().CreateFilter(ti.args)
+			if ti.err {
+				if err == nil {
+					t.Fatal("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := f.(*sameSiteFilter).value; v != ti.value {
+				t.Errorf("expected %s, got %s", ti.value, v)
+			}
+		})
+	}
+}
+
+func TestSameSiteName(t *testing.T) {
+	if n := NewSameSite().Name(); n != filters.CookieSameSiteName {
+		t.Errorf("expected %s, got %s", filters.CookieSameSiteName, n)
+	}
+}
+
+func TestSameSiteResponse(t *testing.T) {
+	f, err := NewSameSite().CreateFilter([]interface{}{"Strict"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ti := range []struct {
+		msg      string
+		cookies  []string
+		expected []string
+	}{{
+		msg: "no cookies",
+	}, {
+		msg:      "cookie without attributes",
+		cookies:  []string{"foo=bar"},
+		expected: []string{"foo=bar; SameSite=Strict"},
+	}, {
+		msg:      "cookie with attributes",
+		cookies:  []string{"foo=bar; Path=/; HttpOnly"},
+		expected: []string{"foo=bar; Path=/; HttpOnly; SameSite=Strict"},
+	}, {
+		msg:      "existing same site replaced",
+		cookies:  []string{"foo=bar; samesite=None; Secure"},
+		expected: []string{"foo=bar; Secure; SameSite=Strict"},
+	}, {
+		msg:      "multiple cookies",
+		cookies:  []string{"foo=bar", "baz=qux; SameSite=Lax"},
+		expected: []string{"foo=bar; SameSite=Strict", "baz=qux; SameSite=Strict"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			rsp := &http.Response{Header: http.Header{}}
+			for _, c := range ti.cookies {
+				rsp.Header.Add(SetCookieHttpHeader, c)
+			}
+
+			f.Response(&filtertest.Context{FResponse: rsp})
+
+			got := rsp.Header.Values(SetCookieHttpHeader)
+			if len(got) != len(ti.expected) {
+				t.Fatalf("expected %v, got %v", ti.expected, got)
+			}
+
+			for i := range got {
+				if got[i] != ti.expected[i] {
+					t.Errorf("expected %s, got %s", ti.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -27,6 +27,27 @@ const (
 
 	// BackendRatelimit is the key used in the state bag to configure backend ratelimit in proxy
 	BackendRatelimit = "backend:ratelimit"
+
+	// BackendSNI is the key used in the state bag to configure the TLS server name of the backend in proxy
+	BackendSNI = "backend:sni"
+
+	// BackendConnectTimeout is the key used in the state bag to configure the timeout of connecting to the backend in proxy
+	BackendConnectTimeout = "backend:connecttimeout"
+
+	// BackendEndpoint is the key used in the state bag by the proxy to pass the selected endpoint of the load balanced backends
+	BackendEndpoint = "backend:endpoint"
+
+	// BackendRetryBudget is the key used in the state bag to configure the retry budget of the backend requests in proxy
+	BackendRetryBudget = "backend:retrybudget"
+
+	// BackendDisableRetry is the key used in the state bag to disable the retries of the backend requests in proxy
+	BackendDisableRetry = "backend:disableretry"
+
+	// BackendHTTP1 is the key used in the state bag to force HTTP/1.1 to the backend in proxy
+	BackendHTTP1 = "backend:http1"
+
+	// FlushInterval is the key used in the state bag to configure the response flush interval in proxy
+	FlushInterval = "response:flushinterval"
 )
 
 // Context object providing state and information that is unique to a request.
@@ -168,6 +189,16 @@ type Registry map[string]Spec
 // ErrInvalidFilterParameters is used in case of invalid filter parameters.
 var ErrInvalidFilterParameters = errors.New("invalid filter parameters")
 
+// ErrRequestBodyTooLarge is returned when reading a request body exceeding
+// the limit set by a filter. The proxy responds to the request with 413
+// Request Entity Too Large.
+var ErrRequestBodyTooLarge = errors.New("request body too large")
+
+// ErrResponseBodyTooLarge is returned when reading a response body with an
+// unknown length that exceeds the limit set by a filter, and it stops
+// streaming the response.
+var ErrResponseBodyTooLarge = errors.New("response body too large")
+
 // Registers a filter specification.
 func (r Registry) Register(s Spec) {
 	name := s.Name()
@@ -203,16 +234,27 @@ const (
 	StatusName                                 = "status"
 	CompressName                               = "compress"
 	DecompressName                             = "decompress"
+	DecompressRequestName                      = "decompressRequest"
 	SetQueryName                               = "setQuery"
 	DropQueryName                              = "dropQuery"
 	InlineContentName                          = "inlineContent"
 	InlineContentIfStatusName                  = "inlineContentIfStatus"
 	FlowIdName                                 = "flowId"
+	RequestIdName                              = "requestId"
+	MaxRequestBodyName                         = "maxRequestBody"
+	MaxResponseBodyName                        = "maxResponseBody"
+	BackendEndpointHeaderName                  = "backendEndpointHeader"
 	XforwardName                               = "xforward"
 	XforwardFirstName                          = "xforwardFirst"
 	RandomContentName                          = "randomContent"
 	RepeatContentName                          = "repeatContent"
 	BackendTimeoutName                         = "backendTimeout"
+	BackendSNIName                             = "backendSNI"
+	ConnectTimeoutName                         = "connectTimeout"
+	RetryBudgetName                            = "retryBudget"
+	DisableRetryName                           = "disableRetry"
+	BackendHTTP1Name                           = "backendHTTP1"
+	FlushIntervalName                          = "flushInterval"
 	LatencyName                                = "latency"
 	BandwidthName                              = "bandwidth"
 	ChunksName                                 = "chunks"
@@ -261,6 +303,7 @@ const (
 	OidcClaimsQueryName                        = "oidcClaimsQuery"
 	ResponseCookieName                         = "responseCookie"
 	JsCookieName                               = "jsCookie"
+	CookieSameSiteName                         = "cookieSameSite"
 	ConsecutiveBreakerName                     = "consecutiveBreaker"
 	RateBreakerName                            = "rateBreaker"
 	DisableBreakerName                         = "disableBreaker"
@@ -286,6 +329,7 @@ const (
 	ApiUsageMonitoringName                     = "apiUsageMonitoring"
 	LifoName                                   = "lifo"
 	LifoGroupName                              = "lifoGroup"
+	ClientConcurrencyName                      = "clientConcurrency"
 	RfcPathName                                = "rfcPath"
 	RfcHostName                                = "rfcHost"
 	BearerInjectorName                         = "bearerinjector"
@@ -298,6 +342,7 @@ const (
 	EndpointCreatedName                        = "endpointCreated"
 	ConsistentHashKeyName                      = "consistentHashKey"
 	ConsistentHashBalanceFactorName            = "consistentHashBalanceFactor"
+	ConsistentHashFallbackName                 = "consistentHashFallback"
 
 	// Undocumented filters
 	HealthCheckName        = "healthcheck"
@@ -0,0 +1,73 @@
+package flowid
+
+import (
+	"log"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// RequestIdHeaderName is the default header set by the requestId filter.
+const RequestIdHeaderName = "X-Request-Id"
+
+type requestIdSpec struct {
+	generator Generator
+}
+
+type requestId struct {
+	header    string
+	generator Generator
+}
+
+// NewRequestId creates a new instance of the requestId filter spec, which uses the ULID Generator.
+// To use another type of Generator use NewRequestIdWithGenerator()
+func NewRequestId() filters.Spec {
+	return NewRequestIdWithGenerator(NewULIDGenerator())
+}
+
+// NewRequestIdWithGenerator behaves like NewRequestId but allows you to specify any other Generator.
+func NewRequestIdWithGenerator(g Generator) filters.Spec {
+	return &requestIdSpec{generator: g}
+}
+
+// Name returns the canonical filter name
+func (*requestIdSpec) Name() string { return filters.RequestIdName }
+
+// CreateFilter will return a new requestId filter from the spec. The filter takes an optional
+// argument, the name of the header, which defaults to X-Request-Id.
+func (spec *requestIdSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) > 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	header := RequestIdHeaderName
+	if len(args) == 1 {
+		h, ok := args[0].(string)
+		if !ok || h == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		header = h
+	}
+
+	return &requestId{header: header, generator: spec.generator}, nil
+}
+
+// Request sets the request ID header to a newly generated ID, when the header is not already
+// set in the request. Existing values are kept regardless of their format.
+func (f *requestId) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+	if r.Header.Get(f.header) != "" {
+		return
+	}
+
+	id, err := f.generator.Generate()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	r.Header.Set(f.header, id)
+}
+
+// Response is No-Op in this filter
+func (*requestId) Response(filters.FilterContext) {}
@@ -0,0 +1,71 @@
+package flowid
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+)
+
+func TestRequestId(t *testing.T) {
+	spec := NewRequestId()
+	for _, test := range []struct {
+		title    string
+		args     []interface{}
+		header   string
+		existing string
+	}{{
+		title:  "generate when missing",
+		header: RequestIdHeaderName,
+	}, {
+		title:    "keep existing",
+		header:   RequestIdHeaderName,
+		existing: "3f2c1e0a-2b7e-4d2c-9a8e-9d1c2f4b5a6e",
+	}, {
+		title:  "custom header",
+		args:   []interface{}{"X-Correlation-Id"},
+		header: "X-Correlation-Id",
+	}, {
+		title:    "custom header, keep existing",
+		args:     []interface{}{"X-Correlation-Id"},
+		header:   "X-Correlation-Id",
+		existing: "foo",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			f, err := spec.CreateFilter(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var fc filters.FilterContext
+			if test.existing != "" {
+				fc = buildfilterContext(test.header, test.existing)
+			} else {
+				fc = buildfilterContext()
+			}
+
+			f.Request(fc)
+
+			id := fc.Request().Header.Get(test.header)
+			if test.existing != "" && id != test.existing {
+				t.Errorf("expected the existing request id to be kept, got: %s", id)
+			}
+
+			if test.existing == "" && len(id) != flowIDLength {
+				t.Errorf("expected a generated request id, got: %q", id)
+			}
+		})
+	}
+}
+
+func TestRequestIdInvalidParameters(t *testing.T) {
+	spec := NewRequestId()
+	for _, args := range [][]interface{}{
+		{42},
+		{""},
+		{"X-Request-Id", "X-Correlation-Id"},
+	} {
+		if _, err := spec.CreateFilter(args); err != filters.ErrInvalidFilterParameters {
+			t.Errorf("expected an invalid parameters error for %v, got: %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/zalando/skipper/filters"
+	snet "github.com/zalando/skipper/net"
+	"github.com/zalando/skipper/scheduler"
+)
+
+type (
+	clientConcurrencySpec struct{}
+
+	clientConcurrencyFilter struct {
+		max int
+
+		mu       sync.Mutex
+		inflight map[string]int
+	}
+)
+
+// NewClientConcurrency creates a filter limiting the number of the
+// concurrent requests of a single client, identified by its remote
+// address, to the route. The requests above the limit are rejected
+// with 429 Too Many Requests.
+func NewClientConcurrency() filters.Spec {
+	return &clientConcurrencySpec{}
+}
+
+func (*clientConcurrencySpec) Name() string { return filters.ClientConcurrencyName }
+
+// CreateFilter creates a clientConcurrency filter. It expects a single
+// parameter, the maximum number of concurrent requests per client.
+func (*clientConcurrencySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	max, err := intArg(args[0])
+	if err != nil || max <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &clientConcurrencyFilter{max: max, inflight: make(map[string]int)}, nil
+}
+
+// Request counts the request of the client, or rejects it, when the
+// client has reached the maximum number of concurrent requests. The
+// release of the request is passed to the proxy the same way as the
+// one of the lifo filters, so that it is executed even when the
+// response filters are not.
+func (f *clientConcurrencyFilter) Request(ctx filters.FilterContext) {
+	var key string
+	if ip := snet.RemoteHost(ctx.Request()); ip != nil {
+		key = ip.String()
+	}
+
+	f.mu.Lock()
+	if f.inflight[key] >= f.max {
+		f.mu.Unlock()
+		ctx.Serve(&http.Response{StatusCode: http.StatusTooManyRequests})
+		return
+	}
+
+	f.inflight[key]++
+	f.mu.Unlock()
+
+	done := func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.inflight[key] <= 1 {
+			delete(f.inflight, key)
+		} else {
+			f.inflight[key]--
+		}
+	}
+
+	pending, _ := ctx.StateBag()[scheduler.LIFOKey].([]func())
+	ctx.StateBag()[scheduler.LIFOKey] = append(pending, done)
+}
+
+// Response releases the request of the client.
+func (f *clientConcurrencyFilter) Response(ctx filters.FilterContext) {
+	response(scheduler.LIFOKey, ctx)
+}
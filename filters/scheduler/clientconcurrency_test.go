@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestClientConcurrencyArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{0},
+		{-1},
+		{"2"},
+		{2, 3},
+	} {
+		if _, err := NewClientConcurrency().CreateFilter(args); err == nil {
+			t.Errorf("expected error for args: %v", args)
+		}
+	}
+
+	if _, err := NewClientConcurrency().CreateFilter([]interface{}{float64(2)}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientConcurrency(t *testing.T) {
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		arrived <- struct{}{}
+		<-release
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewClientConcurrency())
+	p := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.ClientConcurrencyName, Args: []interface{}{2}}},
+		Backend: backend.URL,
+	})
+	defer p.Close()
+
+	get := func(client string) int {
+		req, err := http.NewRequest("GET", p.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("X-Forwarded-For", client)
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+			return 0
+		}
+
+		rsp.Body.Close()
+		return rsp.StatusCode
+	}
+
+	var wg sync.WaitGroup
+	for _, client := range []string{"10.0.0.1", "10.0.0.1", "10.0.0.2"} {
+		wg.Add(1)
+		go func(client string) {
+			defer wg.Done()
+			if code := get(client); code != http.StatusOK {
+				t.Errorf("expected status %d for %s, got: %d", http.StatusOK, client, code)
+			}
+		}(client)
+
+		<-arrived
+	}
+
+	if code := get("10.0.0.1"); code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got: %d", http.StatusTooManyRequests, code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	go func() { <-arrived }()
+	if code := get("10.0.0.1"); code != http.StatusOK {
+		t.Errorf("expected status %d after the requests were released, got: %d", http.StatusOK, code)
+	}
+}
@@ -1,6 +1,8 @@
 package tee
 
 import (
+	"sync/atomic"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
 	teepredicate "github.com/zalando/skipper/predicates/tee"
@@ -13,6 +15,8 @@ const FilterName = filters.TeeLoopbackName
 type teeLoopbackSpec struct{}
 type teeLoopbackFilter struct {
 	teeKey string
+	every  uint64
+	count  uint64
 }
 
 func (t *teeLoopbackSpec) Name() string {
@@ -21,15 +25,40 @@ func (t *teeLoopbackSpec) Name() string {
 
 func (t *teeLoopbackSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
 
-	if len(args) != 1 {
+	if len(args) < 1 || len(args) > 2 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 	teeKey, _ := args[0].(string)
 	if teeKey == "" {
 		return nil, filters.ErrInvalidFilterParameters
 	}
+
+	// the optional second argument tees only every n-th request
+	every := uint64(1)
+	if len(args) == 2 {
+		var n int
+		switch v := args[1].(type) {
+		case int:
+			n = v
+		case float64:
+			n = int(v)
+			if float64(n) != v {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+		default:
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if n < 1 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		every = uint64(n)
+	}
+
 	return &teeLoopbackFilter{
-		teeKey,
+		teeKey: teeKey,
+		every:  every,
 	}, nil
 }
 
@@ -38,6 +67,10 @@ func NewTeeLoopback() filters.Spec {
 }
 
 func (f *teeLoopbackFilter) Request(ctx filters.FilterContext) {
+	if f.every > 1 && atomic.AddUint64(&f.count, 1)%f.every != 0 {
+		return
+	}
+
 	cc, err := ctx.Split()
 	if err != nil {
 		log.Errorf("teeloopback: failed to split the context request: %v", err)
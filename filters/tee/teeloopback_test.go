@@ -194,3 +194,65 @@ func TestLoopbackWithClientIP(t *testing.T) {
 		)
 	}
 }
+
+func TestLoopbackEvery(t *testing.T) {
+	const routeFmt = `
+		split: Path("/foo") -> teeLoopback("A", 3) -> "%v";
+		shadow: Path("/foo") && Tee("A") -> "%v";
+	`
+
+	const listenFor = 30 * time.Millisecond
+	split := backendtest.NewBackendRecorder(listenFor)
+	shadow := backendtest.NewBackendRecorder(listenFor)
+
+	routes, err := eskip.Parse(fmt.Sprintf(routeFmt, split.GetURL(), shadow.GetURL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := make(filters.Registry)
+	registry.Register(NewTeeLoopback())
+	p := proxytest.WithRoutingOptions(registry, routing.Options{
+		Predicates: []routing.PredicateSpec{
+			teePredicate.New(),
+		},
+	}, routes...)
+	defer p.Close()
+
+	for i := 0; i < 7; i++ {
+		rsp, err := http.Get(p.URL + "/foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+	}
+
+	waitForAll(split, shadow)
+	if !matchRequestsCount(split, 7) || !matchRequestsCount(shadow, 2) {
+		t.Errorf(
+			"expected 7 requests in the split backend and 2 in the shadow backend, got split: %d, shadow: %d",
+			len(split.GetRequests()),
+			len(shadow.GetRequests()),
+		)
+	}
+}
+
+func TestLoopbackEveryArgs(t *testing.T) {
+	spec := NewTeeLoopback()
+	for _, args := range [][]interface{}{
+		{"A", float64(0)},
+		{"A", float64(-1)},
+		{"A", 1.5},
+		{"A", "10"},
+		{"A", float64(10), float64(1)},
+	} {
+		if _, err := spec.CreateFilter(args); err != filters.ErrInvalidFilterParameters {
+			t.Errorf("expected an invalid parameters error for %v, got: %v", args, err)
+		}
+	}
+
+	if _, err := spec.CreateFilter([]interface{}{"A", float64(10)}); err != nil {
+		t.Error(err)
+	}
+}
@@ -41,6 +41,7 @@ const powerOfRandomNChoicesDefaultN = 2
 const (
 	ConsistentHashKey           = "consistentHashKey"
 	ConsistentHashBalanceFactor = "consistentHashBalanceFactor"
+	ConsistentHashFallback      = "consistentHashFallback"
 )
 
 var (
@@ -244,6 +245,47 @@ func (ch consistentHash) search(key string) int {
 	return ch[ringIndex].index
 }
 
+// Fallback contains the endpoints removed from the hash ring of a
+// consistentHash route, and the algorithm used to balance the requests whose
+// keys were mapped to them, instead of rehashing them to the neighbouring
+// endpoints of the ring.
+type Fallback struct {
+	removed   consistentHash
+	algorithm routing.LBAlgorithm
+}
+
+// NewFallback creates the fallback of a consistentHash route, for the
+// endpoints removed from the route.
+func NewFallback(a Algorithm, removed []string) *Fallback {
+	if len(removed) == 0 {
+		return &Fallback{}
+	}
+
+	initialize := defaultAlgorithm
+	if a != None && a != ConsistentHash {
+		initialize = algorithms[a]
+	}
+
+	return &Fallback{
+		removed:   newConsistentHash(removed).(consistentHash),
+		algorithm: initialize(removed),
+	}
+}
+
+// owns tells whether the key was mapped to one of the removed endpoints, i.e.
+// whether the closest hash of the removed endpoints comes before the closest
+// hash of the current endpoints on the ring.
+func (f *Fallback) owns(ch consistentHash, key string) bool {
+	if len(f.removed) == 0 {
+		return false
+	}
+
+	h := hash(key)
+	current := ch[ch.searchRing(key)].hash - h
+	removed := f.removed[f.removed.searchRing(key)].hash - h
+	return removed < current
+}
+
 func computeLoadAverage(ctx *routing.LBContext) float64 {
 	sum := 1.0 // add 1 to include the request that just arrived
 	endpoints := ctx.Route.LBEndpoints
@@ -283,6 +325,10 @@ func (ch consistentHash) Apply(ctx *routing.LBContext) routing.LBEndpoint {
 	if !ok {
 		key = net.RemoteHost(ctx.Request).String()
 	}
+
+	if f, ok := ctx.Params[ConsistentHashFallback].(*Fallback); ok && f.owns(ch, key) {
+		return f.algorithm.Apply(ctx)
+	}
 	balanceFactor, ok := ctx.Params[ConsistentHashBalanceFactor].(float64)
 	var choice int
 	if !ok {
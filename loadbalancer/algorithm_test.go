@@ -374,6 +374,55 @@ func TestConsistentHashKey(t *testing.T) {
 	}
 }
 
+func TestConsistentHashFallback(t *testing.T) {
+	before := []string{"http://127.0.0.1:8080", "http://127.0.0.2:8080", "http://127.0.0.3:8080"}
+	after := before[:2]
+	previous := newConsistentHash(before).(consistentHash)
+
+	r, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
+	rt := NewAlgorithmProvider().Do([]*routing.Route{{
+		Route: eskip.Route{
+			BackendType: eskip.LBBackend,
+			LBAlgorithm: ConsistentHash.String(),
+			LBEndpoints: after,
+		},
+	}})[0]
+
+	ch := rt.LBAlgorithm.(consistentHash)
+	fallback := NewFallback(Random, before[2:])
+
+	var rehashed int
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		removed := previous.search(key) == 2
+		if fallback.owns(ch, key) != removed {
+			t.Fatalf("expected the fallback to own the key %s: %v", key, removed)
+		}
+
+		selected := ch.Apply(&routing.LBContext{Request: r, Route: rt, Params: map[string]interface{}{
+			ConsistentHashKey:      key,
+			ConsistentHashFallback: fallback,
+		}})
+
+		if removed {
+			rehashed++
+			continue
+		}
+
+		if expected := rt.LBEndpoints[previous.search(key)]; selected != expected {
+			t.Errorf("expected the key %s to stay on %v, got: %v", key, expected, selected)
+		}
+	}
+
+	if rehashed == 0 {
+		t.Error("expected keys mapped to the removed endpoint")
+	}
+
+	if NewFallback(Random, nil).owns(ch, "session-1") {
+		t.Error("expected no fallback without removed endpoints")
+	}
+}
+
 func TestConsistentHashBoundedLoadDistribution(t *testing.T) {
 	endpoints := []string{"http://127.0.0.1:8080", "http://127.0.0.2:8080", "http://127.0.0.3:8080"}
 	r, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
@@ -0,0 +1,138 @@
+/*
+Package clientcert implements a predicate to match the attributes of the TLS
+client certificate presented with the request.
+*/
+package clientcert
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+const (
+	// AttributeSubject matches the subject distinguished name of the client
+	// certificate, in RFC 2253 format, e.g. CN=admin,O=Example
+	AttributeSubject = "subject"
+
+	// AttributeIssuer matches the issuer distinguished name of the client
+	// certificate, in RFC 2253 format
+	AttributeIssuer = "issuer"
+
+	// AttributeSAN matches any of the DNS names, email addresses, URIs or
+	// IP addresses of the subject alternative names of the client certificate
+	AttributeSAN = "san"
+)
+
+type (
+	spec struct{}
+
+	matcher struct {
+		attribute string
+		value     string
+	}
+
+	predicate struct {
+		matchers []matcher
+	}
+)
+
+// New creates a predicate specification, whose instances match the
+// attributes of the leaf TLS client certificate of the request.
+//
+// The predicate accepts pairs of arguments, the attribute, one of subject,
+// issuer or san, and the value that the attribute must be equal to. All the
+// pairs must match. Requests without a client certificate don't match.
+//
+// Eskip example:
+//
+// 	ClientCert("subject", "CN=admin") -> "https://www.example.org";
+//
+func New() routing.PredicateSpec { return &spec{} }
+
+func (*spec) Name() string { return predicates.ClientCertName }
+
+func (*spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	var matchers []matcher
+	for i := 0; i < len(args); i += 2 {
+		attribute, ok := args[i].(string)
+		if !ok {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		switch attribute {
+		case AttributeSubject, AttributeIssuer, AttributeSAN:
+		default:
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		value, ok := args[i+1].(string)
+		if !ok {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		matchers = append(matchers, matcher{attribute: attribute, value: value})
+	}
+
+	return &predicate{matchers: matchers}, nil
+}
+
+func matchSAN(cert *x509.Certificate, value string) bool {
+	for _, n := range cert.DNSNames {
+		if n == value {
+			return true
+		}
+	}
+
+	for _, e := range cert.EmailAddresses {
+		if e == value {
+			return true
+		}
+	}
+
+	for _, u := range cert.URIs {
+		if u.String() == value {
+			return true
+		}
+	}
+
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m matcher) match(cert *x509.Certificate) bool {
+	switch m.attribute {
+	case AttributeSubject:
+		return cert.Subject.String() == m.value
+	case AttributeIssuer:
+		return cert.Issuer.String() == m.value
+	default:
+		return matchSAN(cert, m.value)
+	}
+}
+
+func (p *predicate) Match(r *http.Request) bool {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	for _, m := range p.matchers {
+		if !m.match(cert) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,130 @@
+package clientcert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClientCertArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{{
+		"no args",
+		nil,
+		true,
+	}, {
+		"missing value",
+		[]interface{}{"subject"},
+		true,
+	}, {
+		"unknown attribute",
+		[]interface{}{"serial", "42"},
+		true,
+	}, {
+		"invalid value",
+		[]interface{}{"subject", float64(42)},
+		true,
+	}, {
+		"ok",
+		[]interface{}{"subject", "CN=admin"},
+		false,
+	}, {
+		"multiple attributes",
+		[]interface{}{"subject", "CN=admin", "san", "admin.example.org"},
+		false,
+	}} {
+		_, err := New().Create(ti.args)
+		if ti.err && err == nil {
+			t.Error(ti.msg, "failed to fail")
+		} else if !ti.err && err != nil {
+			t.Error(ti.msg, err)
+		}
+	}
+}
+
+func TestClientCertMatch(t *testing.T) {
+	u, _ := url.Parse("spiffe://example.org/admin")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "admin", Organization: []string{"Example"}},
+		Issuer:         pkix.Name{CommonName: "Example CA"},
+		DNSNames:       []string{"admin.example.org"},
+		EmailAddresses: []string{"admin@example.org"},
+		URIs:           []*url.URL{u},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+
+	for _, ti := range []struct {
+		msg   string
+		args  []interface{}
+		tls   *tls.ConnectionState
+		match bool
+	}{{
+		"no tls",
+		[]interface{}{"subject", "CN=admin,O=Example"},
+		nil,
+		false,
+	}, {
+		"no client certificate",
+		[]interface{}{"subject", "CN=admin,O=Example"},
+		&tls.ConnectionState{},
+		false,
+	}, {
+		"subject",
+		[]interface{}{"subject", "CN=admin,O=Example"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"subject mismatch",
+		[]interface{}{"subject", "CN=admin"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		false,
+	}, {
+		"issuer",
+		[]interface{}{"issuer", "CN=Example CA"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"san dns name",
+		[]interface{}{"san", "admin.example.org"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"san email",
+		[]interface{}{"san", "admin@example.org"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"san uri",
+		[]interface{}{"san", "spiffe://example.org/admin"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"san ip",
+		[]interface{}{"san", "10.0.0.1"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		true,
+	}, {
+		"all attributes must match",
+		[]interface{}{"subject", "CN=admin,O=Example", "san", "other.example.org"},
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		false,
+	}} {
+		p, err := New().Create(ti.args)
+		if err != nil {
+			t.Error(ti.msg, err)
+			continue
+		}
+
+		r := &http.Request{TLS: ti.tls}
+		if m := p.Match(r); m != ti.match {
+			t.Error(ti.msg, "unexpected match result", m, ti.match)
+		}
+	}
+}
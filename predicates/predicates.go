@@ -43,4 +43,7 @@ const (
 	ClientIPName              = "ClientIP"
 	TeeName                   = "Tee"
 	TrafficName               = "Traffic"
+	ClientCertName            = "ClientCert"
+	SourceSplitName           = "SourceSplit"
+	SourceSplitFromLastName   = "SourceSplitFromLast"
 )
@@ -0,0 +1,80 @@
+package source
+
+import (
+	"hash/fnv"
+	"math"
+	"net"
+	"net/http"
+
+	snet "github.com/zalando/skipper/net"
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type splitSpec struct {
+	typ sourcePred
+}
+
+type splitPredicate struct {
+	typ   sourcePred
+	ratio float64
+}
+
+// NewSplit creates a predicate specification, whose instances match a
+// consistent ratio of the source IPs of the requests, e.g. SourceSplit(0.2)
+// matches the requests from 20% of the clients. The source IPs are hashed,
+// so the same client is always matched or not matched by the same ratio, and
+// the clients matched by a lower ratio are also matched by the higher ones.
+// The source IP is determined the same way as by the Source predicate.
+func NewSplit() routing.PredicateSpec { return &splitSpec{typ: source} }
+
+// NewSplitFromLast creates a predicate specification like NewSplit, but it
+// determines the source IP the same way as the SourceFromLast predicate.
+func NewSplitFromLast() routing.PredicateSpec { return &splitSpec{typ: sourceFromLast} }
+
+func (s *splitSpec) Name() string {
+	if s.typ == sourceFromLast {
+		return predicates.SourceSplitFromLastName
+	}
+
+	return predicates.SourceSplitName
+}
+
+func (s *splitSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) != 1 {
+		return nil, InvalidArgsError
+	}
+
+	ratio, ok := args[0].(float64)
+	if !ok || ratio < 0 || ratio > 1 {
+		return nil, InvalidArgsError
+	}
+
+	return &splitPredicate{typ: s.typ, ratio: ratio}, nil
+}
+
+// sourceBucket maps the IP to a value in [0, 1)
+func sourceBucket(ip net.IP) float64 {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	h := fnv.New32a()
+	h.Write(ip)
+	return float64(h.Sum32()) / (math.MaxUint32 + 1)
+}
+
+func (p *splitPredicate) Match(r *http.Request) bool {
+	var src net.IP
+	if p.typ == sourceFromLast {
+		src = snet.RemoteHostFromLast(r)
+	} else {
+		src = snet.RemoteHost(r)
+	}
+
+	if src == nil {
+		return false
+	}
+
+	return sourceBucket(src) < p.ratio
+}
@@ -0,0 +1,131 @@
+package source
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/predicates"
+)
+
+func TestSplitName(t *testing.T) {
+	if s := NewSplit().Name(); s != predicates.SourceSplitName {
+		t.Fatalf("Failed to get Name %s, got %s", predicates.SourceSplitName, s)
+	}
+	if s := NewSplitFromLast().Name(); s != predicates.SourceSplitFromLastName {
+		t.Fatalf("Failed to get Name %s, got %s", predicates.SourceSplitFromLastName, s)
+	}
+}
+
+func TestSplitCreate(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{{
+		"no args",
+		nil,
+		true,
+	}, {
+		"not a number",
+		[]interface{}{"0.2"},
+		true,
+	}, {
+		"negative ratio",
+		[]interface{}{-0.1},
+		true,
+	}, {
+		"ratio greater than 1",
+		[]interface{}{1.1},
+		true,
+	}, {
+		"too many args",
+		[]interface{}{0.1, 0.2},
+		true,
+	}, {
+		"valid ratio",
+		[]interface{}{0.2},
+		false,
+	}} {
+		_, err := NewSplit().Create(ti.args)
+		if ti.err && err == nil {
+			t.Error(ti.msg, "failed to fail")
+		} else if !ti.err && err != nil {
+			t.Error(ti.msg, err)
+		}
+	}
+}
+
+func splitMatches(t *testing.T, ratio float64, n int) map[string]bool {
+	p, err := NewSplit().Create([]interface{}{ratio})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		ip := fmt.Sprintf("10.%d.%d.%d", i/65536, i/256%256, i%256)
+		r := &http.Request{RemoteAddr: ip + ":4242", Header: http.Header{}}
+		if p.Match(r) {
+			m[ip] = true
+		}
+	}
+
+	return m
+}
+
+func TestSplitMatch(t *testing.T) {
+	const n = 10000
+
+	m20 := splitMatches(t, 0.2, n)
+	if len(m20) < n*15/100 || len(m20) > n*25/100 {
+		t.Errorf("unexpected number of matching source IPs: %d of %d", len(m20), n)
+	}
+
+	// the same clients are matched again
+	again := splitMatches(t, 0.2, n)
+	if len(again) != len(m20) {
+		t.Errorf("inconsistent matches: %d, %d", len(again), len(m20))
+	}
+
+	// the clients of the lower ratio are contained by the higher ratio
+	m30 := splitMatches(t, 0.3, n)
+	for ip := range m20 {
+		if !m30[ip] {
+			t.Errorf("%s matched by the lower ratio but not by the higher one", ip)
+		}
+	}
+
+	if m := splitMatches(t, 0, n); len(m) != 0 {
+		t.Errorf("expected no match for ratio 0, got: %d", len(m))
+	}
+
+	if m := splitMatches(t, 1, n); len(m) != n {
+		t.Errorf("expected all to match for ratio 1, got: %d", len(m))
+	}
+}
+
+func TestSplitMatchForwarded(t *testing.T) {
+	p, err := NewSplit().Create([]interface{}{1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pl, err := NewSplitFromLast().Create([]interface{}{1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &http.Request{
+		RemoteAddr: "10.0.0.1:4242",
+		Header:     http.Header{"X-Forwarded-For": []string{"1.2.3.4, 5.6.7.8"}},
+	}
+
+	if !p.Match(r) || !pl.Match(r) {
+		t.Error("failed to match the forwarded request")
+	}
+
+	if sourceBucket([]byte{1, 2, 3, 4}) == sourceBucket([]byte{5, 6, 7, 8}) {
+		t.Error("unexpected equal buckets of different IPs")
+	}
+}
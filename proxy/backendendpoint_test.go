@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestBackendEndpointHeader(t *testing.T) {
+	backends := make(map[string]bool)
+	var urls []string
+	for i := 0; i < 2; i++ {
+		s := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer s.Close()
+
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		backends[u.Host] = true
+		urls = append(urls, s.URL)
+	}
+
+	doc := fmt.Sprintf(`
+		lb: Path("/lb") -> backendEndpointHeader("X-Backend-Endpoint") -> <"%s", "%s">;
+		network: Path("/network") -> backendEndpointHeader("X-Backend-Endpoint") -> "%s";
+	`, urls[0], urls[1], urls[0])
+
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	served := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		rsp, err := http.Get(ps.URL + "/lb")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		endpoint := rsp.Header.Get("X-Backend-Endpoint")
+		if !backends[endpoint] {
+			t.Fatalf("unexpected backend endpoint: %q", endpoint)
+		}
+
+		served[endpoint] = true
+	}
+
+	if len(served) != len(backends) {
+		t.Errorf("expected all the endpoints to serve requests, got: %v", served)
+	}
+
+	rsp, err := http.Get(ps.URL + "/network")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	if endpoint := rsp.Header.Get("X-Backend-Endpoint"); endpoint != "" {
+		t.Errorf("unexpected backend endpoint for a network backend: %q", endpoint)
+	}
+}
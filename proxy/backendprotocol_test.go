@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The proxy doesn't negotiate HTTP/2 with the backends, not even with the
+// TLS backends supporting it.
+func TestBackendRequestHTTP1(t *testing.T) {
+	service := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend-Proto", r.Proto)
+	}))
+	service.EnableHTTP2 = true
+	service.StartTLS()
+	defer service.Close()
+
+	for _, test := range []struct {
+		title  string
+		params Params
+	}{{
+		title:  "insecure",
+		params: Params{Flags: Insecure},
+	}, {
+		title:  "client TLS",
+		params: Params{ClientTLS: service.Client().Transport.(*http.Transport).TLSClientConfig},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			tp, err := newTestProxyWithParams(fmt.Sprintf(`* -> "%s"`, service.URL), test.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			rsp, err := http.Get(ps.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp.Body.Close()
+			if p := rsp.Header.Get("X-Backend-Proto"); p != "HTTP/1.1" {
+				t.Errorf("expected HTTP/1.1 to the backend, got: %s", p)
+			}
+		})
+	}
+}
+
+func TestBackendHTTP1PreventsH2CUpgrade(t *testing.T) {
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend-Upgrade", r.Header.Get("Upgrade"))
+		w.Header().Set("X-Backend-Proto", r.Proto)
+	}))
+	defer service.Close()
+
+	tp, err := newTestProxyWithParams(fmt.Sprintf(`* -> backendHTTP1() -> "%s"`, service.URL), Params{ExperimentalUpgrade: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	req, err := http.NewRequest("GET", ps.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Connection", "Upgrade, HTTP2-Settings")
+	req.Header.Set("Upgrade", "h2c")
+	req.Header.Set("HTTP2-Settings", "AAMAAABkAARAAAAAAAIAAAAA")
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rsp.StatusCode)
+	}
+
+	if u := rsp.Header.Get("X-Backend-Upgrade"); u != "" {
+		t.Errorf("expected no upgrade to the backend, got: %s", u)
+	}
+
+	if p := rsp.Header.Get("X-Backend-Proto"); p != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1 to the backend, got: %s", p)
+	}
+}
@@ -0,0 +1,20 @@
+package proxy
+
+import (
+	stdlibcontext "context"
+	"time"
+)
+
+type connectTimeoutKey struct{}
+
+// contextWithConnectTimeout returns a context carrying the timeout of
+// connecting to the backend, set with the connectTimeout filter, to be
+// applied by the dialer of the transport.
+func contextWithConnectTimeout(ctx stdlibcontext.Context, timeout time.Duration) stdlibcontext.Context {
+	return stdlibcontext.WithValue(ctx, connectTimeoutKey{}, timeout)
+}
+
+func connectTimeoutFromContext(ctx stdlibcontext.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(connectTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	stdlibcontext "context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnectTimeout(t *testing.T) {
+	// the dial blocks until the dial context is done
+	dialer := &skipperDialer{f: func(ctx stdlibcontext.Context, _, _ string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+
+	ctx, cancel := stdlibcontext.WithTimeout(stdlibcontext.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := dialer.DialContext(contextWithConnectTimeout(ctx, 50*time.Millisecond), "tcp", "10.0.0.1:80")
+	if err == nil {
+		t.Fatal("expected a dial error")
+	}
+
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("dial took %v, expected the connect timeout to apply", d)
+	}
+
+	perr, ok := err.(*proxyError)
+	if !ok || !perr.DialError() {
+		t.Errorf("expected a dial error, got: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDisableRetryForNonIdempotentMethods(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer backend.Close()
+
+	// an address refusing the connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := "http://" + l.Addr().String()
+	l.Close()
+
+	doc := fmt.Sprintf(`
+		idempotent: Path("/") -> <roundRobin, "%[1]s", "%[2]s">;
+		nonIdempotent: Path("/") && Method("POST") -> disableRetry() -> <roundRobin, "%[1]s", "%[2]s">;
+	`, closed, backend.URL)
+
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	failed := func(method string) int {
+		var count int
+		for i := 0; i < 4; i++ {
+			req, err := http.NewRequest(method, ps.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp.Body.Close()
+			if rsp.StatusCode != http.StatusOK {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	if n := failed("GET"); n != 0 {
+		t.Errorf("expected the failed GET requests to be retried, got %d failures", n)
+	}
+
+	if n := failed("POST"); n == 0 {
+		t.Error("expected the failed POST requests not to be retried")
+	}
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFlushInterval(t *testing.T) {
+	for _, filter := range []string{"", `flushInterval("20ms") ->`} {
+		t.Run(fmt.Sprintf("filter: %q", filter), func(t *testing.T) {
+			release := make(chan struct{})
+			service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("foo"))
+				w.(http.Flusher).Flush()
+				<-release
+				w.Write([]byte("bar"))
+			}))
+			defer service.Close()
+			defer close(release)
+
+			tp, err := newTestProxy(fmt.Sprintf(`* -> %s "%s"`, filter, service.URL), FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			rsp, err := http.Get(ps.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+
+			// the data written before the backend stops writing needs to
+			// be flushed to the client
+			received := make(chan string, 1)
+			go func() {
+				b := make([]byte, 3)
+				io.ReadFull(rsp.Body, b)
+				received <- string(b)
+			}()
+
+			select {
+			case b := <-received:
+				if b != "foo" {
+					t.Errorf("expected foo, got: %s", b)
+				}
+			case <-time.After(time.Second):
+				t.Error("timeout waiting for the flushed data")
+			}
+		})
+	}
+}
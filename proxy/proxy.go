@@ -17,6 +17,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ot "github.com/opentracing/opentracing-go"
@@ -320,6 +321,7 @@ type Proxy struct {
 	defaultHTTPStatus        int
 	routing                  *routing.Routing
 	roundTripper             http.RoundTripper
+	sniTransports            *sniTransports
 	priorityRoutes           []PriorityRoute
 	flags                    Flags
 	metrics                  metrics.Metrics
@@ -414,10 +416,68 @@ func (f *flusher) Write(p []byte) (n int, err error) {
 	return
 }
 
-func copyStream(to flushedResponseWriter, from io.Reader) (int64, error) {
+// intervalFlusher flushes the written data at most once per interval. The
+// data written after the last flush is flushed by a timer, so it is not kept
+// buffered when the writes stop.
+type intervalFlusher struct {
+	w        flushedResponseWriter
+	interval time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+}
+
+func (f *intervalFlusher) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err = f.w.Write(p)
+	if err != nil || f.pending {
+		return
+	}
+
+	f.pending = true
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.interval, f.delayedFlush)
+	} else {
+		f.timer.Reset(f.interval)
+	}
+
+	return
+}
+
+func (f *intervalFlusher) delayedFlush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.pending {
+		f.w.Flush()
+		f.pending = false
+	}
+}
+
+func (f *intervalFlusher) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = false
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+}
+
+// copyStream copies the response body, flushing it after every write, or,
+// when the flush interval is set, at most once per interval.
+func copyStream(to flushedResponseWriter, from io.Reader, flushInterval time.Duration) (int64, error) {
 	b := make([]byte, proxyBufferSize)
+	if flushInterval <= 0 {
+		return io.CopyBuffer(&flusher{to}, from, b)
+	}
 
-	return io.CopyBuffer(&flusher{to}, from, b)
+	f := &intervalFlusher{w: to, interval: flushInterval}
+	defer f.stop()
+	return io.CopyBuffer(f, from, b)
 }
 
 func schemeFromRequest(r *http.Request) string {
@@ -559,7 +619,15 @@ func (dc *skipperDialer) DialContext(ctx stdlibcontext.Context, network, addr st
 	if span != nil {
 		span.LogKV("dial_context", "start")
 	}
-	con, err := dc.f(ctx, network, addr)
+
+	dialCtx := ctx
+	if timeout, ok := connectTimeoutFromContext(ctx); ok {
+		var cancel stdlibcontext.CancelFunc
+		dialCtx, cancel = stdlibcontext.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	con, err := dc.f(dialCtx, network, addr)
 	if span != nil {
 		span.LogKV("dial_context", "done")
 	}
@@ -631,6 +699,7 @@ func WithParams(p Params) *Proxy {
 		Proxy:                 proxyFromHeader,
 	}
 
+	sni := newSNITransports(tr, p.CustomHttpRoundTripperWrap)
 	quit := make(chan struct{})
 	// We need this to reliably fade on DNS change, which is right
 	// now not fixed with IdleConnTimeout in the http.Transport.
@@ -641,6 +710,7 @@ func WithParams(p Params) *Proxy {
 				select {
 				case <-time.After(p.CloseIdleConnsPeriod):
 					tr.CloseIdleConnections()
+					sni.closeIdleConnections()
 				case <-quit:
 					return
 				}
@@ -684,6 +754,7 @@ func WithParams(p Params) *Proxy {
 	return &Proxy{
 		routing:                  p.Routing,
 		roundTripper:             p.CustomHttpRoundTripperWrap(tr),
+		sniTransports:            sni,
 		priorityRoutes:           p.PriorityRoutes,
 		flags:                    p.Flags,
 		metrics:                  m,
@@ -851,6 +922,9 @@ func (p *Proxy) makeUpgradeRequest(ctx *context, req *http.Request) error {
 
 	reverseProxy := httputil.NewSingleHostReverseProxy(backendURL)
 	reverseProxy.FlushInterval = p.flushInterval
+	if flushInterval, ok := ctx.StateBag()[filters.FlushInterval].(time.Duration); ok {
+		reverseProxy.FlushInterval = flushInterval
+	}
 	upgradeProxy := upgradeProxy{
 		backendAddr:     backendURL,
 		reverseProxy:    reverseProxy,
@@ -879,10 +953,15 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 	}
 
 	if endpoint != nil {
+		ctx.StateBag()[filters.BackendEndpoint] = endpoint.Host
 		endpoint.Metrics.IncInflightRequest()
 		defer endpoint.Metrics.DecInflightRequest()
 	}
 
+	if http1, _ := ctx.StateBag()[filters.BackendHTTP1].(bool); http1 {
+		removeHTTP2Upgrade(req)
+	}
+
 	if p.experimentalUpgrade && isUpgradeRequest(req) {
 		if err = p.makeUpgradeRequest(ctx, req); err != nil {
 			return nil, &proxyError{err: err}
@@ -947,6 +1026,8 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 			perr.err = fmt.Errorf("failed to do backend roundtrip to %s: %w", req.URL.Host, perr.err)
 			return nil, perr
 
+		} else if errors.Is(err, filters.ErrRequestBodyTooLarge) {
+			return nil, &proxyError{err: fmt.Errorf("failed to send the request body to %s: %w", req.URL.Host, err), code: http.StatusRequestEntityTooLarge}
 		} else if nerr, ok := err.(net.Error); ok {
 			//p.lb.AddHealthcheck(ctx.route.Backend)
 			var status int
@@ -991,6 +1072,10 @@ func (p *Proxy) getRoundTripper(ctx *context, req *http.Request) (http.RoundTrip
 
 		return rt, nil
 	default:
+		if serverName, ok := ctx.StateBag()[filters.BackendSNI].(string); ok && req.URL.Scheme == "https" {
+			return p.sniTransports.get(serverName), nil
+		}
+
 		return p.roundTripper, nil
 	}
 }
@@ -1118,6 +1203,10 @@ func (p *Proxy) do(ctx *context) error {
 			backendContext, ctx.cancelBackendContext = stdlibcontext.WithTimeout(backendContext, timeout.(time.Duration))
 		}
 
+		if timeout, ok := ctx.StateBag()[filters.BackendConnectTimeout].(time.Duration); ok {
+			backendContext = contextWithConnectTimeout(backendContext, timeout)
+		}
+
 		backendStart := time.Now()
 		rsp, perr := p.makeBackendRequest(ctx, backendContext)
 		if perr != nil {
@@ -1127,7 +1216,8 @@ func (p *Proxy) do(ctx *context) error {
 
 			p.metrics.IncErrorsBackend(ctx.route.Id)
 
-			if retryable(ctx, perr) {
+			if release, ok := allowRetry(ctx, perr); ok {
+				defer release()
 				if ctx.proxySpan != nil {
 					ctx.proxySpan.Finish()
 					ctx.proxySpan = nil
@@ -1169,12 +1259,31 @@ func (p *Proxy) do(ctx *context) error {
 }
 
 func retryable(ctx *context, perr *proxyError) bool {
+	if disabled, _ := ctx.StateBag()[filters.BackendDisableRetry].(bool); disabled {
+		return false
+	}
+
 	req := ctx.Request()
 	return perr.code != 499 && perr.DialError() &&
 		ctx.route.BackendType == eskip.LBBackend &&
 		req != nil && (req.Body == nil || req.Body == http.NoBody)
 }
 
+// allowRetry tells whether the failed backend request can be retried, and
+// reserves the retry from the retry budget of the route, when set.
+func allowRetry(ctx *context, perr *proxyError) (func(), bool) {
+	if !retryable(ctx, perr) {
+		return nil, false
+	}
+
+	budget, ok := ctx.StateBag()[filters.BackendRetryBudget].(func() (func(), bool))
+	if !ok {
+		return func() {}, true
+	}
+
+	return budget()
+}
+
 func (p *Proxy) serveResponse(ctx *context) {
 	if p.flags.Debug() {
 		dbgResponse(ctx.responseWriter, &debugInfo{
@@ -1206,7 +1315,8 @@ func (p *Proxy) serveResponse(ctx *context) {
 	ctx.responseWriter.Flush()
 	p.tracing.logStreamEvent(ctx.proxySpan, StreamHeadersEvent, EndEvent)
 
-	n, err := copyStream(ctx.responseWriter, ctx.response.Body)
+	flushInterval, _ := ctx.StateBag()[filters.FlushInterval].(time.Duration)
+	n, err := copyStream(ctx.responseWriter, ctx.response.Body, flushInterval)
 	p.tracing.logStreamEvent(ctx.proxySpan, StreamBodyEvent, strconv.FormatInt(n, 10))
 	if err != nil {
 		p.metrics.IncErrorsStreaming(ctx.route.Id)
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRetryBudget(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer backend.Close()
+
+	// an address refusing the connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := "http://" + l.Addr().String()
+	l.Close()
+
+	doc := fmt.Sprintf(`
+		noBudget: Path("/no-budget") -> <roundRobin, "%[1]s", "%[2]s">;
+		minBudget: Path("/min-budget") -> retryBudget(0.0, 1) -> <roundRobin, "%[1]s", "%[2]s">;
+		zeroBudget: Path("/zero-budget") -> retryBudget(0.0, 0) -> <roundRobin, "%[1]s", "%[2]s">;
+	`, closed, backend.URL)
+
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	failed := func(path string) int {
+		var count int
+		for i := 0; i < 4; i++ {
+			rsp, err := http.Get(ps.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp.Body.Close()
+			if rsp.StatusCode != http.StatusOK {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	if n := failed("/no-budget"); n != 0 {
+		t.Errorf("expected the failed requests to be retried without a budget, got %d failures", n)
+	}
+
+	if n := failed("/min-budget"); n != 0 {
+		t.Errorf("expected the failed requests to be retried within the minimum budget, got %d failures", n)
+	}
+
+	if n := failed("/zero-budget"); n == 0 {
+		t.Error("expected the failed requests not to be retried with an empty budget")
+	}
+}
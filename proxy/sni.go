@@ -0,0 +1,58 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+)
+
+// sniTransports holds the transports used for the backends requiring a TLS
+// server name different from their host, set with the backendSNI filter.
+// The transports are clones of the default transport, one per server name,
+// so that the pooled connections are not shared between the server names.
+type sniTransports struct {
+	base *http.Transport
+	wrap func(http.RoundTripper) http.RoundTripper
+
+	mu            sync.Mutex
+	transports    []*http.Transport
+	roundTrippers map[string]http.RoundTripper
+}
+
+func newSNITransports(base *http.Transport, wrap func(http.RoundTripper) http.RoundTripper) *sniTransports {
+	return &sniTransports{
+		base:          base,
+		wrap:          wrap,
+		roundTrippers: make(map[string]http.RoundTripper),
+	}
+}
+
+func (s *sniTransports) get(serverName string) http.RoundTripper {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rt, ok := s.roundTrippers[serverName]; ok {
+		return rt
+	}
+
+	tr := s.base.Clone()
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+
+	tr.TLSClientConfig.ServerName = serverName
+
+	rt := s.wrap(tr)
+	s.transports = append(s.transports, tr)
+	s.roundTrippers[serverName] = rt
+	return rt
+}
+
+func (s *sniTransports) closeIdleConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tr := range s.transports {
+		tr.CloseIdleConnections()
+	}
+}
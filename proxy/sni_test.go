@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBackendSNI(t *testing.T) {
+	service := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server-Name", r.TLS.ServerName)
+	}))
+	defer service.Close()
+
+	doc := fmt.Sprintf(`
+		sni: Path("/sni") -> backendSNI("internal.svc") -> "%s";
+		other: Path("/other") -> backendSNI("other.svc") -> "%s";
+		default: * -> "%s";
+	`, service.URL, service.URL, service.URL)
+
+	tp, err := newTestProxy(doc, Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	for _, test := range []struct {
+		path       string
+		serverName string
+	}{
+		{"/sni", "internal.svc"},
+		{"/other", "other.svc"},
+		{"/", ""},
+		{"/sni", "internal.svc"},
+	} {
+		rsp, err := http.Get(ps.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		if rsp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected 200, got: %d", test.path, rsp.StatusCode)
+		}
+
+		if sn := rsp.Header.Get("X-Server-Name"); sn != test.serverName {
+			t.Errorf("%s: expected server name %q, got: %q", test.path, test.serverName, sn)
+		}
+	}
+}
@@ -36,6 +36,19 @@ func getUpgradeRequest(req *http.Request) string {
 	return ""
 }
 
+// removeHTTP2Upgrade removes the headers of the upgrade to HTTP/2 over
+// cleartext from the request, so that the connection to the backend is not
+// upgraded, and the backend responds with HTTP/1.1.
+func removeHTTP2Upgrade(req *http.Request) {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "h2c") {
+		return
+	}
+
+	req.Header.Del("Upgrade")
+	req.Header.Del("Http2-Settings")
+	req.Header.Del("Connection")
+}
+
 // UpgradeProxy stores everything needed to make the connection upgrade.
 type upgradeProxy struct {
 	backendAddr     *url.URL
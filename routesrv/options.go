@@ -100,6 +100,10 @@ type Options struct {
 	// used with external name services (type=ExternalName).
 	KubernetesAllowedExternalNames []*regexp.Regexp
 
+	// KubernetesAllowLocalExternalNames allows the external name services pointing to loopback,
+	// link-local or cloud metadata addresses.
+	KubernetesAllowLocalExternalNames bool
+
 	// WhitelistedHealthcheckCIDR appends the whitelisted IP Range to the inernalIPS range for healthcheck purposes
 	WhitelistedHealthCheckCIDR []string
 
@@ -49,6 +49,7 @@ func New(opts Options) (*RouteServer, error) {
 
 	dataclient, err := kubernetes.New(kubernetes.Options{
 		AllowedExternalNames:              opts.KubernetesAllowedExternalNames,
+		AllowLocalExternalNames:           opts.KubernetesAllowLocalExternalNames,
 		BackendNameTracingTag:             opts.OpenTracingBackendNameTag,
 		DefaultFiltersDir:                 opts.DefaultFiltersDir,
 		KubernetesIngressV1:               opts.KubernetesIngressV1,
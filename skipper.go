@@ -41,6 +41,7 @@ import (
 	"github.com/zalando/skipper/metrics"
 	skpnet "github.com/zalando/skipper/net"
 	pauth "github.com/zalando/skipper/predicates/auth"
+	"github.com/zalando/skipper/predicates/clientcert"
 	"github.com/zalando/skipper/predicates/cookie"
 	"github.com/zalando/skipper/predicates/cron"
 	"github.com/zalando/skipper/predicates/forwarded"
@@ -222,6 +223,10 @@ type Options struct {
 	// used with external name services (type=ExternalName).
 	KubernetesAllowedExternalNames []*regexp.Regexp
 
+	// KubernetesAllowLocalExternalNames allows the external name services pointing to loopback,
+	// link-local or cloud metadata addresses.
+	KubernetesAllowLocalExternalNames bool
+
 	// *DEPRECATED* API endpoint of the Innkeeper service, storing route definitions.
 	InnkeeperUrl string
 
@@ -914,6 +919,7 @@ func createDataClients(o Options, auth innkeeper.Authentication, cr *certregistr
 		kubernetesClient, err := kubernetes.New(kubernetes.Options{
 			KubernetesIngressV1:               o.KubernetesIngressV1,
 			AllowedExternalNames:              o.KubernetesAllowedExternalNames,
+			AllowLocalExternalNames:           o.KubernetesAllowLocalExternalNames,
 			BackendNameTracingTag:             o.OpenTracingBackendNameTag,
 			DefaultFiltersDir:                 o.DefaultFiltersDir,
 			KubernetesInCluster:               o.KubernetesInCluster,
@@ -1526,6 +1532,8 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		source.New(),
 		source.NewFromLast(),
 		source.NewClientIP(),
+		source.NewSplit(),
+		source.NewSplitFromLast(),
 		interval.NewBetween(),
 		interval.NewBefore(),
 		interval.NewAfter(),
@@ -1545,6 +1553,7 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		forwarded.NewForwardedHost(),
 		forwarded.NewForwardedProto(),
 		host.NewAny(),
+		clientcert.New(),
 	)
 
 	// provide default value for wrapper if not defined